@@ -1,5 +1,11 @@
 package gofileparser
 
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
 // ParseGoFile parses a Go source file and returns a GFP_GoFile structure.
 //
 // Parameters:
@@ -12,21 +18,439 @@ package gofileparser
 // This function is the main entry point for parsing a Go file. It reads the file,
 // parses its contents, and returns a structured representation of the Go file.
 // If any error occurs during file reading or parsing, it returns nil and the error.
+//
+// Constants are evaluated with go/constant into GFP_Constant.ExactValue as far as the file
+// alone allows, resolving iota, implicit repetition and references to other constants of
+// the file. With GFP_Options.TypeCheck, the values come from go/types instead.
 func ParseGoFile(filePath string) (*GFPGoFile, error) {
-	return parseGoFile(filePath)
+	return NewParser(GFPOptions{}).ParseFile(filePath)
+}
+
+// ParseGoSource parses Go source held in memory and returns a GFP_GoFile structure.
+//
+// Parameters:
+//   - name: string - The name used for the file in positions and in GFP_GoFile.FileName.
+//   - src: []byte - The Go source to be parsed.
+//
+// Returns:
+//   - *GFP_GoFile: A pointer to the parsed file structure.
+//   - error: Any error encountered during parsing.
+//
+// This function behaves like ParseGoFile but never touches the filesystem, which makes
+// it suitable for generated code or sources that only exist in memory.
+func ParseGoSource(name string, src []byte) (*GFPGoFile, error) {
+	return NewParser(GFPOptions{}).ParseSource(name, src)
 }
 
-// ParseGoPackage parses all Go files in a directory and returns a slice of GFP_GoFile structures.
+// ParseGoReader reads Go source from an io.Reader and returns a GFP_GoFile structure.
+//
+// Parameters:
+//   - name: string - The name used for the file in positions and in GFP_GoFile.FileName.
+//   - r: io.Reader - The reader providing the Go source.
+//
+// Returns:
+//   - *GFP_GoFile: A pointer to the parsed file structure.
+//   - error: Any error encountered while reading or parsing.
+//
+// The reader is consumed until EOF before parsing starts.
+func ParseGoReader(name string, r io.Reader) (*GFPGoFile, error) {
+	return NewParser(GFPOptions{}).ParseReader(name, r)
+}
+
+// ParseGoPackage parses all Go files in a directory and returns a GFP_Package structure.
 //
 // Parameters:
 //   - dirPath: string - The path to the directory containing Go files.
 //
 // Returns:
-//   - []*GFP_GoFile: A slice of pointers to the parsed file structures.
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
 //   - error: Any error encountered during parsing.
 //
 // This function parses all .go files in the specified directory, excluding test files.
-// It returns a slice of parsed file structures and any error encountered during the process.
-func ParseGoPackage(dirPath string) ([]*GFPGoFile, error) {
-	return parseGoPackage(dirPath)
+// The individual files remain available in GFP_Package.Files. It returns an error if the
+// directory has no Go files or if the files declare different package names. The import
+// path is derived from the nearest enclosing go.mod, if any. Constants referring to
+// constants of other files of the package are evaluated as well.
+func ParseGoPackage(dirPath string) (*GFPPackage, error) {
+	return NewParser(GFPOptions{}).ParsePackage(dirPath)
+}
+
+// ParseGoPackageFS parses all Go files in a directory of an fs.FS and returns a GFP_Package structure.
+//
+// Parameters:
+//   - fsys: fs.FS - The file system to read from (e.g. an embed.FS or os.DirFS).
+//   - dir: string - The slash-separated directory within fsys; use "." for the root.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
+//   - error: Any error encountered during parsing.
+//
+// This function applies the same rules as ParseGoPackage, excluding test files. File names
+// are recorded as their paths within fsys, and the import path is derived from a go.mod
+// found in dir or one of its parents within fsys.
+func ParseGoPackageFS(fsys fs.FS, dir string) (*GFPPackage, error) {
+	return NewParser(GFPOptions{}).ParsePackageFS(fsys, dir)
+}
+
+// ParseGoModule parses every package of the Go module rooted at a directory.
+//
+// Parameters:
+//   - root: string - The directory containing the module's go.mod file.
+//
+// Returns:
+//   - *GFP_Module: A pointer to the parsed module, with packages keyed by import path.
+//   - error: Any error encountered while reading go.mod or parsing a package.
+//
+// Like the go tool, this function skips testdata and vendor directories, directories whose
+// names start with "." or "_", and nested modules. Directories without non-test Go files
+// do not produce a package.
+func ParseGoModule(root string) (*GFPModule, error) {
+	return NewParser(GFPOptions{}).ParseModule(root)
+}
+
+// FindImplementations reports which concrete types in the given packages implement an interface.
+//
+// Parameters:
+//   - iface: GFP_Interface - The interface to look for, e.g. taken from GFP_Package.Interfaces.
+//   - ifacePkg: *GFP_Package - The package declaring the interface, used to resolve the
+//     names in its method signatures and embeds. It may be nil, in which case names are
+//     compared as written and embeds are not resolved.
+//   - pkgs: ...*GFP_Package - The packages whose types are checked.
+//
+// Returns:
+//   - []GFP_Implementation: One entry per type that implements the interface or shares at
+//     least one method name with it (a near-miss), in package and declaration order.
+//
+// Method sets follow the Go rules: values only have the value-receiver methods, pointers
+// have all methods. Embedded interfaces are resolved through the imports of the declaring
+// file, among pkgs or else by type-checking the standard library or module package they
+// come from. Embeds that cannot be resolved are listed in UnresolvedEmbeds, and since
+// their methods cannot be checked the type is not reported to implement the interface
+// (ByValue and ByPointer are false). Methods promoted from
+// embedded struct fields are not considered. Signatures are compared by their resolved
+// types when parsed in type-checked mode and otherwise by their source text, with names
+// qualified by the package that declares them, so that "Item" in package store and
+// "store.Item" elsewhere match.
+func FindImplementations(iface GFPInterface, ifacePkg *GFPPackage, pkgs ...*GFPPackage) []GFPImplementation {
+	return findImplementations([]qualifiedInterface{{iface: iface, pkg: ifacePkg}}, pkgs)
+}
+
+// ImplementationMatrix checks every concrete type against every interface of the given packages.
+//
+// Parameters:
+//   - pkgs: ...*GFP_Package - The packages whose interfaces and types are checked.
+//
+// Returns:
+//   - []GFP_Implementation: The implementations and near-misses of all interfaces, in
+//     package and declaration order.
+//
+// See FindImplementations for the rules that are applied. Interfaces without any methods
+// are skipped, since every type would implement them.
+func ImplementationMatrix(pkgs ...*GFPPackage) []GFPImplementation {
+	return findImplementations(packageInterfaces(pkgs), pkgs)
+}
+
+// FindEnums detects the enums declared in the given packages, following the idiom
+// "type Color int" with "const ( Red Color = iota; Green; Blue )".
+//
+// Parameters:
+//   - pkgs: ...*GFP_Package - The packages to search.
+//
+// Returns:
+//   - []GFP_Enum: One entry per defined type based on a predeclared basic type that has at
+//     least one constant, in package and declaration order.
+//
+// A constant belongs to an enum if it is declared with the enum type or its value is a
+// conversion to the type ("Red = Color(iota)"), in both cases also through implicit
+// repetition in an iota block. Blank constants are skipped, and aliases ("type Code = int")
+// are not enum types. Members carry their evaluated values, which are more
+// complete when the packages were parsed in type-checked mode.
+func FindEnums(pkgs ...*GFPPackage) []GFPEnum {
+	return findEnums(pkgs)
+}
+
+// GenerateMock generates the source of a mock implementation of an interface.
+//
+// Parameters:
+//   - iface: GFP_Interface - The interface to implement.
+//   - pkg: *GFP_Package - The package that declares the interface. Its imports and
+//     declarations are needed to qualify the types of the generated code.
+//   - opts: GFP_MockOptions - The package the mock is generated into and its type name.
+//
+// Returns:
+//   - []byte: The gofmt'd source of a Go file declaring the mock.
+//   - error: An error if the interface cannot be implemented from the target package, an
+//     embedded interface cannot be expanded or a type refers to a package that is not
+//     imported.
+//
+// The mock has a function field per method (e.g. GetFunc for Get) that the method calls
+// with its arguments; if the field is nil, the method returns zero values. Every call is
+// recorded and returned by a method such as GetCalls, whose records hold the arguments in
+// fields named after the parameters, with variadic arguments as a slice. Mocks of generic
+// interfaces are generic over the same type parameters. Methods of embedded interfaces are
+// mocked as well: those of the same package are taken from their declarations, those of
+// other packages, such as io.Closer, are type-checked from the standard library or the
+// module of pkg. Embedded generic interfaces and interfaces that cannot be loaded, e.g.
+// from other modules, cause an error.
+func GenerateMock(iface GFPInterface, pkg *GFPPackage, opts GFPMockOptions) ([]byte, error) {
+	return generateMock(iface, pkg, opts)
+}
+
+// BuildCallGraph builds the static call graph of the given packages.
+//
+// Parameters:
+//   - pkgs: ...*GFP_Package - The packages whose function and method bodies are analysed.
+//
+// Returns:
+//   - *GFP_CallGraph: The graph, which can be queried with Callers and Callees.
+//
+// Calls are resolved syntactically from the GFP_Call entries recorded while parsing. Calls
+// on parameters or the receiver are resolved through their declared types, which also
+// tells calls through interfaces and through function-typed fields apart. A call is made
+// through an interface if the parameter's resolved type is one, which needs type-checked
+// mode for interfaces outside pkgs such as io.Writer, or if its declared type is an
+// interface of pkgs. Calls on other local variables keep their source text as the callee.
+func BuildCallGraph(pkgs ...*GFPPackage) *GFPCallGraph {
+	return buildCallGraph(pkgs)
+}
+
+// ToJSON encodes a parse result as a versioned JSON document.
+//
+// Parameters:
+//   - v: any - The result to encode: a *GFP_GoFile, []*GFP_GoFile, *GFP_Package, []*GFP_Package,
+//     *GFP_Module, *GFP_CallGraph, []GFP_Implementation or []GFP_Enum.
+//   - opts: GFP_JSONOptions - Indentation and the parts of the result to leave out.
+//
+// Returns:
+//   - []byte: The encoded GFP_Document.
+//   - error: An error if v is not one of the supported types.
+//
+// Field names are lowerCamelCase and empty optional fields are omitted. The document
+// records SchemaVersion in "schemaVersion" and holds the result under "file", "files",
+// "package", "packages", "module", "callGraph", "implementations" or "enums". The result itself
+// is not modified when content or bodies are omitted.
+func ToJSON(v any, opts GFPJSONOptions) ([]byte, error) {
+	return toJSON(v, opts)
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the documents written by ToJSON.
+//
+// Returns:
+//   - []byte: The indented schema, with one definition per GFP type under "$defs".
+//   - error: Any error encountered while encoding the schema.
+//
+// The schema is derived from the GFP types themselves, so it always matches the output of
+// ToJSON for the current SchemaVersion.
+func JSONSchema() ([]byte, error) {
+	return jsonSchema()
+}
+
+// ParseDocComment parses the text of a documentation comment into headings, paragraphs,
+// lists, code blocks and doc links, following the go/doc/comment syntax.
+//
+// Parameters:
+//   - text: string - The comment text without comment markers, such as the Doc field of
+//     a declaration.
+//
+// Returns:
+//   - *GFP_DocComment: A pointer to the parsed comment, or nil if text is empty.
+//
+// Doc links to packages are only resolved for the standard library, since the imports
+// of the surrounding file are unknown. Parsed declarations already carry their parsed
+// comment in DocComment, with package links resolved through the file's imports.
+func ParseDocComment(text string) *GFPDocComment {
+	return parseDocComment(text, nil)
+}
+
+// ParseFile parses a Go source file with the parser's options.
+//
+// Parameters:
+//   - filePath: string - The path to the Go source file to be parsed.
+//
+// Returns:
+//   - *GFP_GoFile: A pointer to the parsed file structure.
+//   - error: Any error encountered during parsing.
+//
+// See ParseGoFile. In tolerant mode, syntax errors are reported in GFP_GoFile.Diagnostics
+// and only read errors or unrecoverable files produce an error.
+func (p *GFPParser) ParseFile(filePath string) (*GFPGoFile, error) {
+	return p.parseGoFile(filePath)
+}
+
+// ParseSource parses Go source held in memory with the parser's options.
+//
+// Parameters:
+//   - name: string - The name used for the file in positions and in GFP_GoFile.FileName.
+//   - src: []byte - The Go source to be parsed.
+//
+// Returns:
+//   - *GFP_GoFile: A pointer to the parsed file structure.
+//   - error: Any error encountered during parsing.
+//
+// See ParseGoSource.
+func (p *GFPParser) ParseSource(name string, src []byte) (*GFPGoFile, error) {
+	return p.parseGoSource(name, src)
+}
+
+// ParseReader reads Go source from an io.Reader and parses it with the parser's options.
+//
+// Parameters:
+//   - name: string - The name used for the file in positions and in GFP_GoFile.FileName.
+//   - r: io.Reader - The reader providing the Go source.
+//
+// Returns:
+//   - *GFP_GoFile: A pointer to the parsed file structure.
+//   - error: Any error encountered while reading or parsing.
+//
+// See ParseGoReader.
+func (p *GFPParser) ParseReader(name string, r io.Reader) (*GFPGoFile, error) {
+	return p.parseGoReader(name, r)
+}
+
+// ParsePackage parses all Go files in a directory with the parser's options.
+//
+// Parameters:
+//   - dirPath: string - The path to the directory containing Go files.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
+//   - error: Any error encountered during parsing.
+//
+// See ParseGoPackage.
+func (p *GFPParser) ParsePackage(dirPath string) (*GFPPackage, error) {
+	return p.parseGoPackage(context.Background(), dirPath)
+}
+
+// ParsePackageContext parses all Go files in a directory with the parser's options until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: context.Context - Cancels parsing; files that have not been started are skipped.
+//   - dirPath: string - The path to the directory containing Go files.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
+//     If some files could not be read or parsed, the package of the other files is
+//     returned along with the error.
+//   - error: ctx.Err() if ctx was cancelled, or the errors of all files that could not be
+//     read or parsed, joined with errors.Join.
+//
+// Files are parsed concurrently by up to GFP_Options.Workers goroutines. The order of the
+// files and symbols does not depend on the number of workers.
+func (p *GFPParser) ParsePackageContext(ctx context.Context, dirPath string) (*GFPPackage, error) {
+	return p.parseGoPackage(ctx, dirPath)
+}
+
+// ParsePackageFS parses all Go files in a directory of an fs.FS with the parser's options.
+//
+// Parameters:
+//   - fsys: fs.FS - The file system to read from (e.g. an embed.FS or os.DirFS).
+//   - dir: string - The slash-separated directory within fsys; use "." for the root.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
+//   - error: Any error encountered during parsing.
+//
+// See ParseGoPackageFS.
+func (p *GFPParser) ParsePackageFS(fsys fs.FS, dir string) (*GFPPackage, error) {
+	return p.parseGoPackageFS(context.Background(), fsys, dir)
+}
+
+// ParsePackageFSContext parses all Go files in a directory of an fs.FS with the parser's options until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: context.Context - Cancels parsing; files that have not been started are skipped.
+//   - fsys: fs.FS - The file system to read from (e.g. an embed.FS or os.DirFS).
+//   - dir: string - The slash-separated directory within fsys; use "." for the root.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the parsed package, with the symbols of all files merged.
+//     If some files could not be read or parsed, the package of the other files is
+//     returned along with the error.
+//   - error: ctx.Err() if ctx was cancelled, or the errors of all files that could not be
+//     read or parsed, joined with errors.Join.
+//
+// See ParsePackageContext.
+func (p *GFPParser) ParsePackageFSContext(ctx context.Context, fsys fs.FS, dir string) (*GFPPackage, error) {
+	return p.parseGoPackageFS(ctx, fsys, dir)
+}
+
+// ParseModule parses every package of the Go module rooted at a directory with the parser's options.
+//
+// Parameters:
+//   - root: string - The directory containing the module's go.mod file.
+//
+// Returns:
+//   - *GFP_Module: A pointer to the parsed module, with packages keyed by import path.
+//   - error: Any error encountered while reading go.mod or parsing a package.
+//
+// See ParseGoModule.
+func (p *GFPParser) ParseModule(root string) (*GFPModule, error) {
+	return p.parseGoModule(context.Background(), root, root)
+}
+
+// ParseModuleContext parses every package of the Go module rooted at a directory with the parser's options until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: context.Context - Cancels walking and parsing; files that have not been started are skipped.
+//   - root: string - The directory containing the module's go.mod file.
+//
+// Returns:
+//   - *GFP_Module: A pointer to the parsed module, with packages keyed by import path.
+//     If some files or packages could not be parsed, the module holds the packages of
+//     the other files and is returned along with the error.
+//   - error: ctx.Err() if ctx was cancelled, an error reading go.mod or walking the tree,
+//     or the errors of all files and packages in the module that could not be read or
+//     parsed, joined with errors.Join.
+//
+// The files of all packages share one pool of up to GFP_Options.Workers goroutines.
+// See ParseGoModule for the packages that are included.
+func (p *GFPParser) ParseModuleContext(ctx context.Context, root string) (*GFPModule, error) {
+	return p.parseGoModule(ctx, root, root)
+}
+
+// ParseModuleTree parses the packages in a directory tree of a Go module with the parser's options.
+//
+// Parameters:
+//   - dir: string - The directory whose tree is parsed, the module root or any directory inside the module.
+//
+// Returns:
+//   - *GFP_Module: A pointer to the module enclosing dir, holding only the packages in the
+//     tree below dir, keyed by import path. If some files or packages could not be parsed,
+//     it holds the packages of the other files and is returned along with the error.
+//   - error: An error if dir is not inside a module, an error reading go.mod or walking the
+//     tree, or the errors of the files and packages below dir that could not be read or
+//     parsed, joined with errors.Join.
+//
+// The go.mod is looked up in dir and its parent directories. Below dir, the same
+// directories are skipped as by ParseGoModule; dir itself is always walked. Nothing outside
+// dir is parsed, like the go tool matching the pattern "dir/...".
+func (p *GFPParser) ParseModuleTree(dir string) (*GFPModule, error) {
+	return p.parseGoModuleTree(context.Background(), dir)
+}
+
+// ParseModuleTreeContext parses the packages in a directory tree of a Go module with the parser's options until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: context.Context - Cancels walking and parsing; files that have not been started are skipped.
+//   - dir: string - The directory whose tree is parsed, the module root or any directory inside the module.
+//
+// Returns:
+//   - *GFP_Module: A pointer to the module enclosing dir, holding only the packages in the
+//     tree below dir, keyed by import path.
+//   - error: ctx.Err() if ctx was cancelled, or any error ParseModuleTree would return.
+//
+// See ParseModuleTree.
+func (p *GFPParser) ParseModuleTreeContext(ctx context.Context, dir string) (*GFPModule, error) {
+	return p.parseGoModuleTree(ctx, dir)
+}
+
+// SortedPackages returns the packages of a parsed module in a deterministic order.
+//
+// Returns:
+//   - []*GFP_Package: The packages of GFP_Module.Packages, ordered by import path.
+//
+// The result can be passed on to the functions that take several packages, such as
+// ImplementationMatrix or BuildCallGraph, to get their results in a stable order.
+func (m *GFPModule) SortedPackages() []*GFPPackage {
+	return m.sortedPackages()
 }
@@ -0,0 +1,73 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+// buildContext returns a copy of the build context that package files are matched against.
+func (p *GFPParser) buildContext() build.Context {
+	if p.opts.BuildContext != nil {
+		return *p.opts.BuildContext
+	}
+	return build.Default
+}
+
+// fsBuildContext returns a copy of ctx that reads files from fsys instead of the disk.
+func fsBuildContext(ctx build.Context, fsys fs.FS) build.Context {
+	ctx.JoinPath = func(elem ...string) string {
+		return path.Join(elem...)
+	}
+	ctx.OpenFile = func(name string) (io.ReadCloser, error) {
+		return fsys.Open(name)
+	}
+	return ctx
+}
+
+// matchBuildContext reports whether a file satisfies the GOOS and GOARCH of its name
+// ("foo_windows_amd64.go") and its //go:build or // +build lines, where the "cgo" tag is
+// satisfied if ctx.CgoEnabled is set. Files importing "C" without a cgo constraint are
+// not excluded. Like the go tool, files whose names start with "_" or "." never match.
+func matchBuildContext(ctx *build.Context, file string) (bool, error) {
+	return ctx.MatchFile(filepath.Dir(file), filepath.Base(file))
+}
+
+// parseBuildConstraint returns the build constraint of a file in its normalized
+// //go:build form (e.g. "linux && (amd64 || arm64)"), or "" if it has none.
+// A //go:build line takes precedence over // +build lines, which are combined with &&.
+// Lines that cannot be parsed are ignored.
+func parseBuildConstraint(file *ast.File) string {
+	var plusBuild []constraint.Expr
+	for _, group := range file.Comments {
+		// Build constraints must appear before the package clause.
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					return expr.String()
+				}
+			case constraint.IsPlusBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					plusBuild = append(plusBuild, expr)
+				}
+			}
+		}
+	}
+
+	if len(plusBuild) == 0 {
+		return ""
+	}
+	expr := plusBuild[0]
+	for _, next := range plusBuild[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: next}
+	}
+	return expr.String()
+}
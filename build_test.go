@@ -0,0 +1,135 @@
+package gofileparser
+
+import (
+	"context"
+	"go/build"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseBuildConstraint(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"None", "package a\n", ""},
+		{"GoBuild", "//go:build linux && (amd64 || arm64)\n\npackage a\n", "linux && (amd64 || arm64)"},
+		{"PlusBuild", "// +build linux darwin\n// +build !cgo\n\npackage a\n", "(linux || darwin) && !cgo"},
+		{"GoBuildWins", "//go:build ignore\n// +build linux\n\npackage a\n", "ignore"},
+		{"AfterDoc", "// Copyright notice.\n\n//go:build tools\n\n// Package a does things.\npackage a\n", "tools"},
+		{"AfterPackageClause", "package a\n\n//go:build linux\n", ""},
+		{"Invalid", "//go:build linux &&\n\npackage a\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goFile, err := NewParser(GFPOptions{}).parseGoSource("a.go", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("parseGoSource failed: %v", err)
+			}
+			if goFile.BuildConstraint != tt.expected {
+				t.Errorf("Expected build constraint %q, got %q", tt.expected, goFile.BuildConstraint)
+			}
+		})
+	}
+}
+
+// buildTestFiles holds a package whose files are selected by build constraints.
+var buildTestFiles = map[string]string{
+	"common.go":       "package sys\n",
+	"sys_linux.go":    "package sys\n\nfunc Name() string { return \"linux\" }\n",
+	"sys_windows.go":  "package sys\n\nfunc Name() string { return \"windows\" }\n",
+	"ignored.go":      "//go:build ignore\n\npackage main\n",
+	"tagged.go":       "//go:build extra\n\npackage sys\n\nfunc Extra() {}\n",
+	"cgo.go":          "//go:build cgo\n\npackage sys\n\nimport \"C\"\n",
+	"_underscored.go": "package sys\n",
+}
+
+func TestParseGoPackageBuildContext(t *testing.T) {
+	dir := t.TempDir()
+	fsys := fstest.MapFS{}
+	for name, content := range buildTestFiles {
+		createTempGoFile(t, dir, name, content)
+		fsys["sys/"+name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		tags     []string
+		cgo      bool
+		expected []string
+	}{
+		{"Linux", "linux", nil, false, []string{"common.go", "sys_linux.go"}},
+		{"Windows", "windows", nil, false, []string{"common.go", "sys_windows.go"}},
+		{"Tags", "linux", []string{"extra"}, false, []string{"common.go", "sys_linux.go", "tagged.go"}},
+		{"Cgo", "linux", nil, true, []string{"cgo.go", "common.go", "sys_linux.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := build.Default
+			ctx.GOOS = tt.goos
+			ctx.GOARCH = "amd64"
+			ctx.BuildTags = tt.tags
+			ctx.CgoEnabled = tt.cgo
+			p := NewParser(GFPOptions{BuildContext: &ctx})
+
+			pkg, err := p.parseGoPackage(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("parseGoPackage failed: %v", err)
+			}
+			var names []string
+			for _, file := range pkg.Files {
+				names = append(names, filepath.Base(file.FileName))
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected files %v, got %v", tt.expected, names)
+			}
+
+			pkg, err = p.parseGoPackageFS(context.Background(), fsys, "sys")
+			if err != nil {
+				t.Fatalf("parseGoPackageFS failed: %v", err)
+			}
+			names = nil
+			for _, file := range pkg.Files {
+				names = append(names, filepath.Base(file.FileName))
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected files %v from fs.FS, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestTypeCheckBuildContext(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	for _, dir := range []string{"sys", "app"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	createTempGoFile(t, filepath.Join(root, "sys"), "sys_linux.go", "package sys\n\ntype Handle int\n")
+	createTempGoFile(t, filepath.Join(root, "sys"), "sys_windows.go", "package sys\n\ntype Handle struct{ ptr uintptr }\n")
+	createTempGoFile(t, filepath.Join(root, "app"), "app.go", "package app\n\nimport \"example.com/mod/sys\"\n\nfunc Open(h sys.Handle) {}\n")
+
+	ctx := build.Default
+	ctx.GOOS = "windows"
+	pkg, err := NewParser(GFPOptions{TypeCheck: true, BuildContext: &ctx}).parseGoPackage(context.Background(), filepath.Join(root, "app"))
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	if diagnostics := pkg.Files[0].Diagnostics; len(diagnostics) != 0 {
+		t.Errorf("Expected no type errors, got %v", diagnostics)
+	}
+	info := pkg.Functions[0].Parameters[0].TypeInfo
+	if info == nil || info.Type != "example.com/mod/sys.Handle" || info.Kind != "struct" {
+		t.Errorf("Expected the windows Handle type, got %+v", info)
+	}
+}
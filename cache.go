@@ -0,0 +1,157 @@
+package gofileparser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheVersion identifies the parser output stored in a GFPCache. It must be changed
+// whenever parsing produces different results for the same source, so that entries
+// written by older versions of the parser are no longer found.
+const cacheVersion = "gofileparser-cache-13"
+
+// GFPCache caches parsed files by path, content hash, parser version and the options that
+// affect the result, so that unchanged files are not parsed again.
+//
+// Entries are kept in memory and, if the cache has a directory, also written to disk so
+// that they survive the process. Every lookup decodes a fresh GFPGoFile, so callers may
+// modify the results. A GFPCache is safe for concurrent use and can be shared between
+// parsers with different options.
+type GFPCache struct {
+	dir     string
+	mu      sync.Mutex
+	entries map[string][]byte
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// NewCache returns a GFPCache that keeps its entries in memory and, unless dir is empty,
+// persists them below dir.
+//
+// Parameters:
+//   - dir: string - The directory to store entries in; it is created when the first entry
+//     is written. Use "" for an in-memory cache.
+//
+// Returns:
+//   - *GFPCache: A pointer to the new cache.
+func NewCache(dir string) *GFPCache {
+	return &GFPCache{dir: dir, entries: make(map[string][]byte)}
+}
+
+// Stats returns the number of lookups that were answered from the cache and that missed it.
+func (c *GFPCache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// key returns the cache key of a file parsed with the given options.
+func (c *GFPCache) key(opts GFPOptions, name string, content []byte) string {
+	contentHash := sha256.Sum256(content)
+	h := sha256.New()
+	h.Write([]byte(cacheVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(contentHash[:])
+	if opts.Tolerant {
+		h.Write([]byte("tolerant"))
+	}
+	if opts.AllComments {
+		h.Write([]byte("all-comments"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns the file stored under key, if any.
+func (c *GFPCache) get(key string) (*GFPGoFile, bool) {
+	c.mu.Lock()
+	data, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if !ok && c.dir != "" {
+		var err error
+		data, err = os.ReadFile(c.path(key))
+		ok = err == nil
+		if ok {
+			c.mu.Lock()
+			c.entries[key] = data
+			c.mu.Unlock()
+		}
+	}
+
+	var goFile GFPGoFile
+	if !ok || json.Unmarshal(data, &goFile) != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return &goFile, true
+}
+
+// put stores goFile under key. Failing to write the entry to disk is not an error; the
+// file is simply parsed again next time.
+func (c *GFPCache) put(key string, goFile *GFPGoFile) {
+	data, err := json.Marshal(goFile)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[key] = data
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so that concurrent readers never see partial entries.
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// path returns the file an entry is stored in, spread over subdirectories by key prefix.
+func (c *GFPCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// parseCached parses Go source into fset like parseSource, consulting the cache of the
+// options first. Cached results come without an AST, so the cache is bypassed in
+// type-checked mode, which needs the ASTs.
+func (p *GFPParser) parseCached(fset *token.FileSet, name string, content []byte) (*GFPGoFile, *ast.File, error) {
+	cache := p.opts.Cache
+	if cache == nil || p.opts.TypeCheck {
+		return p.parseSource(fset, name, content)
+	}
+
+	key := cache.key(p.opts, name, content)
+	if goFile, ok := cache.get(key); ok {
+		return goFile, nil, nil
+	}
+	goFile, file, err := p.parseSource(fset, name, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache.put(key, goFile)
+	return goFile, file, nil
+}
@@ -0,0 +1,174 @@
+package gofileparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const cacheTestSource = `// Package store keeps things.
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// Kind is a kind of item.
+type Kind int
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+// Item is stored.
+type Item[T comparable] struct {
+	ID    T      ` + "`json:\"id\"`" + ` // identifier
+	Label string
+	fmt.Stringer
+}
+
+type Getter interface {
+	Get(ctx context.Context, ids ...string) (Item[string], error)
+	~int | ~string
+}
+
+// Get returns an item.
+func (s *Store) Get(ctx context.Context, id string) (item Item[string], err error) {
+	fmt.Println(id)
+	return
+}
+
+type Store struct{}
+
+func New() *Store { return &Store{} }
+`
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	createTempGoFile(t, dir, "store.go", cacheTestSource)
+	createTempGoFile(t, dir, "other.go", "package store\n\nvar Default = New()\n")
+
+	uncached, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	cache := NewCache("")
+	p := NewParser(GFPOptions{Cache: cache})
+	for i := 0; i < 2; i++ {
+		pkg, err := p.parseGoPackage(context.Background(), dir)
+		if err != nil {
+			t.Fatalf("parseGoPackage with cache failed: %v", err)
+		}
+		if !reflect.DeepEqual(pkg, uncached) {
+			t.Errorf("Run %d: cached package differs from the uncached one", i)
+		}
+	}
+
+	if hits, misses := cache.Stats(); hits != 2 || misses != 2 {
+		t.Errorf("Expected 2 hits and 2 misses, got %d and %d", hits, misses)
+	}
+
+	// Results must not share state with the cache. Files are in name order, so
+	// Files[1] is store.go.
+	first, _ := p.parseGoPackage(context.Background(), dir)
+	first.Files[1].Functions = nil
+	second, _ := p.parseGoPackage(context.Background(), dir)
+	if len(second.Files[1].Functions) == 0 {
+		t.Errorf("Modifying a cached result changed the cache")
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	cache := NewCache("")
+	src := []byte("package a\n\nfunc A() {}\n")
+
+	if _, err := NewParser(GFPOptions{Cache: cache}).parseGoSource("a.go", src); err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    GFPOptions
+		file    string
+		src     []byte
+		wantHit bool
+	}{
+		{"same file", GFPOptions{}, "a.go", src, true},
+		{"other name", GFPOptions{}, "b.go", src, false},
+		{"changed content", GFPOptions{}, "a.go", []byte("package a\n\nfunc B() {}\n"), false},
+		{"tolerant", GFPOptions{Tolerant: true}, "a.go", src, false},
+		{"all comments", GFPOptions{AllComments: true}, "a.go", src, false},
+		{"workers do not matter", GFPOptions{Workers: 3}, "a.go", src, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Cache = cache
+			hitsBefore, _ := cache.Stats()
+			if _, err := NewParser(tt.opts).parseGoSource(tt.file, tt.src); err != nil {
+				t.Fatalf("parseGoSource failed: %v", err)
+			}
+			hits, _ := cache.Stats()
+			if got := hits > hitsBefore; got != tt.wantHit {
+				t.Errorf("Expected hit %v, got %v", tt.wantHit, got)
+			}
+		})
+	}
+}
+
+func TestCacheDisk(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	src := []byte(cacheTestSource)
+
+	want, err := NewParser(GFPOptions{Cache: NewCache(cacheDir)}).parseGoSource("store.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	// A new cache on the same directory, e.g. in the next CI job, finds the entry on disk.
+	cache := NewCache(cacheDir)
+	got, err := NewParser(GFPOptions{Cache: cache}).parseGoSource("store.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	if hits, _ := cache.Stats(); hits != 1 {
+		t.Errorf("Expected the entry to be read from disk")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Entry read from disk differs:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// Corrupt entries are treated as misses.
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "*", "*.json"))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry on disk, got %v", entries)
+	}
+	if err := os.WriteFile(entries[0], []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt entry: %v", err)
+	}
+	cache = NewCache(cacheDir)
+	if _, err := NewParser(GFPOptions{Cache: cache}).parseGoSource("store.go", src); err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 1 {
+		t.Errorf("Expected a miss for a corrupt entry, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestCacheTypeCheckBypass(t *testing.T) {
+	cache := NewCache("")
+	p := NewParser(GFPOptions{Cache: cache, TypeCheck: true})
+	for i := 0; i < 2; i++ {
+		if _, err := p.parseGoSource("a.go", []byte("package a\n\nfunc A(n int) {}\n")); err != nil {
+			t.Fatalf("parseGoSource failed: %v", err)
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 0 {
+		t.Errorf("Expected the cache to be bypassed, got %d hits and %d misses", hits, misses)
+	}
+}
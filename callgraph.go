@@ -0,0 +1,402 @@
+package gofileparser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// builtinFuncs holds the predeclared functions, which are not recorded as calls.
+var builtinFuncs = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true,
+	"copy": true, "delete": true, "imag": true, "len": true, "make": true,
+	"max": true, "min": true, "new": true, "panic": true, "print": true,
+	"println": true, "real": true, "recover": true,
+}
+
+// majorVersionElem matches the major version element of an import path such as "v2".
+var majorVersionElem = regexp.MustCompile(`^v[0-9]+$`)
+
+// importNames maps the names imports are referred to by in a file to their import paths.
+// Blank and dot imports are left out.
+func importNames(imports []*ast.ImportSpec) map[string]string {
+	names := make(map[string]string, len(imports))
+	for _, imp := range imports {
+		importPath := unquote(imp.Path.Value)
+		name := defaultImportName(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name != "_" && name != "." {
+			names[name] = importPath
+		}
+	}
+	return names
+}
+
+// importPaths is importNames for the imports of a parsed file.
+func importPaths(imports []GFPImport) map[string]string {
+	names := make(map[string]string, len(imports))
+	for _, imp := range imports {
+		importPath := unquote(imp.Path)
+		name := imp.Name
+		if name == "" {
+			name = defaultImportName(importPath)
+		}
+		if name != "_" && name != "." {
+			names[name] = importPath
+		}
+	}
+	return names
+}
+
+// defaultImportName guesses the package name of an import path from its last element,
+// skipping major version elements ("example.com/mod/v2") and suffixes ("gopkg.in/yaml.v3").
+func defaultImportName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionElem.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// parseCalls extracts the call sites in the body of a function or method.
+//
+// The extraction is purely syntactic. Calls of parameters and local variables are marked
+// as function value calls, selectors naming an import record its path, and selectors on
+// parameters or the receiver record their declared type for resolution by BuildCallGraph.
+// Builtins and conversions to non-named types are skipped. Calls of function literals, as
+// in "go func() { ... }()", are recorded as "func literal at <line>:<column>".
+func parseCalls(fset *token.FileSet, decl *ast.FuncDecl, imports map[string]string) []GFPCall {
+	if decl.Body == nil {
+		return nil
+	}
+
+	declaredTypes := make(map[string]ast.Expr)
+	for _, list := range []*ast.FieldList{decl.Recv, decl.Type.Params, decl.Type.Results} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				declaredTypes[name.Name] = field.Type
+			}
+		}
+	}
+	locals := localNames(decl.Body)
+	isLocal := func(name string) bool {
+		_, declared := declaredTypes[name]
+		return declared || locals[name]
+	}
+
+	var calls []GFPCall
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		fun := unparen(call.Fun)
+		switch f := fun.(type) {
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		}
+
+		pos := fset.Position(call.Pos())
+		c := GFPCall{Line: pos.Line}
+		switch f := fun.(type) {
+		case *ast.Ident:
+			if builtinFuncs[f.Name] && !isLocal(f.Name) {
+				return true
+			}
+			c.Callee = f.Name
+			c.ViaFuncValue = isLocal(f.Name)
+		case *ast.SelectorExpr:
+			c.Callee = f.Sel.Name
+			c.Selector = exprToString(f.X)
+			if x, ok := f.X.(*ast.Ident); ok {
+				if importPath, ok := imports[x.Name]; ok && !isLocal(x.Name) {
+					c.Package = importPath
+				} else if typ, ok := declaredTypes[x.Name]; ok {
+					c.ReceiverType = qualifiedTypeName(typ, imports)
+				}
+			}
+		case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.StarExpr:
+			// Conversion to a type literal
+			return true
+		case *ast.FuncLit:
+			c.Callee = fmt.Sprintf("func literal at %d:%d", pos.Line, pos.Column)
+			c.ViaFuncValue = true
+		default:
+			c.Callee = exprToString(fun)
+			c.ViaFuncValue = true
+		}
+		calls = append(calls, c)
+		return true
+	})
+	return calls
+}
+
+// localNames returns the names of all variables and functions declared inside a body,
+// including the parameters of function literals.
+func localNames(body *ast.BlockStmt) map[string]bool {
+	names := make(map[string]bool)
+	addIdents := func(exprs []ast.Expr) {
+		for _, expr := range exprs {
+			if ident, ok := expr.(*ast.Ident); ok {
+				names[ident.Name] = true
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			if s.Tok == token.DEFINE {
+				addIdents(s.Lhs)
+			}
+		case *ast.RangeStmt:
+			if s.Tok == token.DEFINE {
+				addIdents([]ast.Expr{s.Key, s.Value})
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				names[name.Name] = true
+			}
+		case *ast.FuncLit:
+			for _, list := range []*ast.FieldList{s.Type.Params, s.Type.Results} {
+				if list == nil {
+					continue
+				}
+				for _, field := range list.List {
+					for _, name := range field.Names {
+						names[name.Name] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// qualifiedTypeName returns the name of the named type a type expression refers to,
+// stripping pointers and type arguments and replacing an import qualifier with its path
+// (e.g. "*l.Handler" becomes "example.com/lib.Handler"). Other types yield their source text.
+func qualifiedTypeName(expr ast.Expr, imports map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return qualifiedTypeName(e.X, imports)
+	case *ast.IndexExpr:
+		return qualifiedTypeName(e.X, imports)
+	case *ast.IndexListExpr:
+		return qualifiedTypeName(e.X, imports)
+	case *ast.ParenExpr:
+		return qualifiedTypeName(e.X, imports)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if importPath, ok := imports[x.Name]; ok {
+				return importPath + "." + e.Sel.Name
+			}
+		}
+	}
+	return exprToString(expr)
+}
+
+// buildCallGraph resolves the recorded calls of pkgs into a GFP_CallGraph.
+func buildCallGraph(pkgs []*GFPPackage) *GFPCallGraph {
+	graph := &GFPCallGraph{}
+
+	interfaces := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, iface := range pkg.Interfaces {
+			interfaces[packagePrefix(pkg)+"."+iface.Name] = true
+		}
+	}
+
+	for _, pkg := range pkgs {
+		prefix := packagePrefix(pkg)
+		resolver := newCallResolver(pkg, prefix, interfaces)
+
+		for _, fn := range pkg.Functions {
+			caller := prefix + "." + fn.Name
+			graph.addNode(caller)
+			params := parameterTypeInfos(fn.Parameters, fn.Results)
+			for _, call := range fn.Calls {
+				graph.addCall(caller, call, resolver, params)
+			}
+		}
+		for _, method := range pkg.Methods {
+			caller := prefix + "." + receiverTypeName(method.Receiver) + "." + method.Name
+			graph.addNode(caller)
+			params := parameterTypeInfos(method.Parameters, method.Results)
+			for _, call := range method.Calls {
+				graph.addCall(caller, call, resolver, params)
+			}
+		}
+	}
+
+	return graph
+}
+
+// packagePrefix returns the prefix of the qualified names of pkg: its import path, or its
+// name if the import path is unknown.
+func packagePrefix(pkg *GFPPackage) string {
+	if pkg.ImportPath == "" {
+		return pkg.Name
+	}
+	return pkg.ImportPath
+}
+
+// parameterTypeInfos maps the named parameters and results of a function to their resolved
+// types. Parameters without type information map to nil.
+func parameterTypeInfos(params, results []GFPParameter) map[string]*GFPTypeInfo {
+	types := make(map[string]*GFPTypeInfo, len(params)+len(results))
+	for _, list := range [][]GFPParameter{params, results} {
+		for _, p := range list {
+			if p.Name != "" {
+				types[p.Name] = p.TypeInfo
+			}
+		}
+	}
+	return types
+}
+
+// callResolver resolves calls made inside one package.
+type callResolver struct {
+	prefix        string
+	functions     map[string]bool
+	types         map[string]GFPType
+	interfaces    map[string]bool // Interfaces of the package, by name
+	allInterfaces map[string]bool // Interfaces of all packages of the graph, by qualified name
+}
+
+// newCallResolver indexes the declarations of pkg for resolving its calls. allInterfaces
+// holds the qualified names of the interfaces of all packages of the graph.
+func newCallResolver(pkg *GFPPackage, prefix string, allInterfaces map[string]bool) *callResolver {
+	r := &callResolver{
+		prefix:        prefix,
+		functions:     make(map[string]bool),
+		types:         make(map[string]GFPType),
+		interfaces:    make(map[string]bool),
+		allInterfaces: allInterfaces,
+	}
+	for _, fn := range pkg.Functions {
+		r.functions[fn.Name] = true
+	}
+	for _, typ := range pkg.Types {
+		r.types[typ.Name] = typ
+	}
+	for _, iface := range pkg.Interfaces {
+		r.interfaces[iface.Name] = true
+	}
+	return r
+}
+
+// resolve returns the qualified name of the callee of a call and updates how the call is
+// made. It reports false for calls that turn out to be type conversions. A call on a
+// parameter is made through an interface if the parameter's resolved type is one, or
+// without type information, if its declared type is an interface of the graph.
+func (r *callResolver) resolve(call *GFPCall, params map[string]*GFPTypeInfo) (string, bool) {
+	if call.Selector == "" {
+		if call.ViaFuncValue {
+			return call.Callee, true
+		}
+		if r.functions[call.Callee] {
+			return r.prefix + "." + call.Callee, true
+		}
+		if _, ok := r.types[call.Callee]; ok || r.interfaces[call.Callee] {
+			return "", false
+		}
+		return call.Callee, true
+	}
+
+	if call.Package != "" {
+		return call.Package + "." + call.Callee, true
+	}
+
+	if typeName := call.ReceiverType; typeName != "" {
+		qualified := typeName
+		if !strings.Contains(typeName, ".") {
+			qualified = r.prefix + "." + typeName
+		}
+		viaInterface := r.allInterfaces[qualified]
+		if info := params[call.Selector]; info != nil {
+			viaInterface = info.Kind == "interface"
+		}
+		if viaInterface {
+			call.ViaInterface = true
+			return qualified + "." + call.Callee, true
+		}
+		if strings.Contains(typeName, ".") {
+			return typeName + "." + call.Callee, true
+		}
+		if typ, ok := r.types[typeName]; ok {
+			for _, field := range typ.Fields {
+				if field.Name == call.Callee && !field.Embedded {
+					call.ViaFuncValue = true
+					break
+				}
+			}
+			return r.prefix + "." + typeName + "." + call.Callee, true
+		}
+	}
+
+	return call.Selector + "." + call.Callee, true
+}
+
+// addNode registers a declared function or method.
+func (g *GFPCallGraph) addNode(name string) {
+	g.Nodes = append(g.Nodes, name)
+}
+
+// addCall resolves a call and adds it as an edge from caller.
+func (g *GFPCallGraph) addCall(caller string, call GFPCall, resolver *callResolver, params map[string]*GFPTypeInfo) {
+	callee, ok := resolver.resolve(&call, params)
+	if !ok {
+		return
+	}
+	g.Edges = append(g.Edges, GFPCallEdge{Caller: caller, Callee: callee, Call: call})
+}
+
+// Callers returns the edges of all calls of the named function or method,
+// e.g. "example.com/mod/pkg.Func" or "example.com/mod/pkg.Type.Method".
+func (g *GFPCallGraph) Callers(name string) []GFPCallEdge {
+	g.buildIndex()
+	return g.edges(g.callers[name])
+}
+
+// Callees returns the edges of all calls made by the named function or method.
+func (g *GFPCallGraph) Callees(name string) []GFPCallEdge {
+	g.buildIndex()
+	return g.edges(g.callees[name])
+}
+
+// buildIndex indexes the edges by caller and callee on first use, so that graphs decoded
+// from JSON can be queried as well. Edges added afterwards are not indexed.
+func (g *GFPCallGraph) buildIndex() {
+	g.index.Do(func() {
+		g.callers = make(map[string][]int)
+		g.callees = make(map[string][]int)
+		for i, edge := range g.Edges {
+			g.callers[edge.Callee] = append(g.callers[edge.Callee], i)
+			g.callees[edge.Caller] = append(g.callees[edge.Caller], i)
+		}
+	})
+}
+
+// edges returns the edges with the given indices.
+func (g *GFPCallGraph) edges(indices []int) []GFPCallEdge {
+	edges := make([]GFPCallEdge, len(indices))
+	for i, index := range indices {
+		edges[i] = g.Edges[index]
+	}
+	return edges
+}
@@ -0,0 +1,232 @@
+package gofileparser
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCalls(t *testing.T) {
+	src := []byte(`package svc
+
+import (
+	"fmt"
+	str "strings"
+	l "example.com/lib"
+)
+
+func Run(h l.Handler, fn func(string) int, items []string) {
+	fmt.Println(str.ToUpper("x"))
+	n := len(items)
+	h.Handle(n)
+	fn("a")
+	cb := func() {}
+	cb()
+	helper()
+	_ = []byte("x")
+	Map[int](items)
+	fmt := "shadowed"
+	fmt.Print()
+	go func() {
+		helper()
+	}()
+}
+`)
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("svc.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	expected := []GFPCall{
+		{Callee: "Println", Selector: "fmt", Line: 10},
+		{Callee: "ToUpper", Selector: "str", Package: "strings", Line: 10},
+		{Callee: "Handle", Selector: "h", ReceiverType: "example.com/lib.Handler", Line: 12},
+		{Callee: "fn", Line: 13, ViaFuncValue: true},
+		{Callee: "cb", Line: 15, ViaFuncValue: true},
+		{Callee: "helper", Line: 16},
+		{Callee: "Map", Line: 18},
+		{Callee: "Print", Selector: "fmt", Line: 20},
+		{Callee: "func literal at 21:5", Line: 21, ViaFuncValue: true},
+		{Callee: "helper", Line: 22},
+	}
+	calls := goFile.Functions[0].Calls
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Calls not parsed correctly:\ngot  %+v\nwant %+v", calls, expected)
+	}
+}
+
+func TestBuildCallGraph(t *testing.T) {
+	src := []byte(`package app
+
+import "fmt"
+
+type Store interface {
+	Get(key string) string
+}
+
+type Service struct {
+	store  Store
+	notify func(string)
+}
+
+type ID int
+
+func NewService(s Store) *Service {
+	svc := &Service{store: s}
+	svc.Start()
+	_ = ID(1)
+	return svc
+}
+
+func (s *Service) Start() {
+	s.Lookup("x")
+	s.notify("started")
+	fmt.Println("started")
+}
+
+func (s *Service) Lookup(key string) string {
+	return s.store.Get(key)
+}
+
+func (s *Service) Fetch(st Store) string {
+	return st.Get("y")
+}
+`)
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("app.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	pkg, err := newPackage(".", "example.com/app", []*GFPGoFile{goFile})
+	if err != nil {
+		t.Fatalf("newPackage failed: %v", err)
+	}
+
+	graph := BuildCallGraph(pkg)
+
+	expectedNodes := []string{
+		"example.com/app.NewService",
+		"example.com/app.Service.Start",
+		"example.com/app.Service.Lookup",
+		"example.com/app.Service.Fetch",
+	}
+	if !reflect.DeepEqual(graph.Nodes, expectedNodes) {
+		t.Errorf("Nodes not correct: %v", graph.Nodes)
+	}
+
+	callees := graph.Callees("example.com/app.NewService")
+	if len(callees) != 1 || callees[0].Callee != "svc.Start" {
+		t.Errorf("Callees of NewService not correct: %+v", callees)
+	}
+
+	callees = graph.Callees("example.com/app.Service.Start")
+	if len(callees) != 3 {
+		t.Fatalf("Expected 3 callees of Start, got %+v", callees)
+	}
+	if callees[0].Callee != "example.com/app.Service.Lookup" || callees[0].Call.ViaFuncValue {
+		t.Errorf("Call of Lookup not resolved: %+v", callees[0])
+	}
+	if callees[1].Callee != "example.com/app.Service.notify" || !callees[1].Call.ViaFuncValue {
+		t.Errorf("Call of function field notify not resolved: %+v", callees[1])
+	}
+	if callees[2].Callee != "fmt.Println" {
+		t.Errorf("Call of fmt.Println not resolved: %+v", callees[2])
+	}
+
+	callers := graph.Callers("example.com/app.Store.Get")
+	if len(callers) != 1 || callers[0].Caller != "example.com/app.Service.Fetch" || !callers[0].Call.ViaInterface {
+		t.Errorf("Interface call of Get not resolved: %+v", callers)
+	}
+
+	callers = graph.Callers("example.com/app.Service.Lookup")
+	if len(callers) != 1 || callers[0].Caller != "example.com/app.Service.Start" {
+		t.Errorf("Callers of Lookup not correct: %+v", callers)
+	}
+
+	if len(graph.Callers("example.com/app.ID")) != 0 {
+		t.Errorf("Conversions should not be recorded as calls")
+	}
+
+	data, err := ToJSON(graph, GFPJSONOptions{})
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	var doc GFPDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	decoded := doc.CallGraph.Callees("example.com/app.Service.Start")
+	if !reflect.DeepEqual(decoded, graph.Callees("example.com/app.Service.Start")) {
+		t.Errorf("Callees of a decoded graph not correct: %+v", decoded)
+	}
+}
+
+func TestBuildCallGraphInterfaceCalls(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/m\n")
+	if err := os.Mkdir(filepath.Join(root, "lib"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	createTempGoFile(t, filepath.Join(root, "lib"), "lib.go", "package lib\n\ntype Source interface {\n\tNext() int\n}\n\ntype Counter struct{}\n\nfunc (c *Counter) Next() int { return 0 }\n")
+	createTempGoFile(t, root, "app.go", `package app
+
+import (
+	"io"
+
+	"example.com/m/lib"
+)
+
+func Copy(w io.Writer, src lib.Source, c *lib.Counter) {
+	w.Write(nil)
+	src.Next()
+	c.Next()
+}
+`)
+
+	for _, typeCheck := range []bool{false, true} {
+		parser := NewParser(GFPOptions{TypeCheck: typeCheck})
+		app, err := parser.parseGoPackage(context.Background(), root)
+		if err != nil {
+			t.Fatalf("parseGoPackage failed: %v", err)
+		}
+		lib, err := parser.parseGoPackage(context.Background(), filepath.Join(root, "lib"))
+		if err != nil {
+			t.Fatalf("parseGoPackage failed: %v", err)
+		}
+
+		// Without type information, io.Writer is not known to be an interface.
+		expected := map[string]bool{"io.Writer.Write": typeCheck, "example.com/m/lib.Source.Next": true, "example.com/m/lib.Counter.Next": false}
+		edges := BuildCallGraph(app, lib).Callees("example.com/m.Copy")
+		if len(edges) != len(expected) {
+			t.Fatalf("Type check %v: expected %d callees, got %+v", typeCheck, len(expected), edges)
+		}
+		for _, edge := range edges {
+			if via, ok := expected[edge.Callee]; !ok || edge.Call.ViaInterface != via {
+				t.Errorf("Type check %v: unexpected edge %s (via interface %v)", typeCheck, edge.Callee, edge.Call.ViaInterface)
+			}
+		}
+	}
+}
+
+func TestDefaultImportName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		expected   string
+	}{
+		{"fmt", "fmt"},
+		{"net/http", "http"},
+		{"example.com/mod/v2", "mod"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			if result := defaultImportName(tt.importPath); result != tt.expected {
+				t.Errorf("defaultImportName() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
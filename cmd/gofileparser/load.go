@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tealwp/gofileparser"
+)
+
+// result holds the parsed targets of a command line. Files given directly and
+// package directories cannot be mixed, so only one of the fields is set.
+type result struct {
+	files []*gofileparser.GFPGoFile
+	pkgs  []*gofileparser.GFPPackage
+}
+
+// allFiles returns every parsed file, including the files of packages.
+func (r *result) allFiles() []*gofileparser.GFPGoFile {
+	files := r.files
+	for _, pkg := range r.pkgs {
+		files = append(files, pkg.Files...)
+	}
+	return files
+}
+
+// diagnostics returns the diagnostics of all parsed files.
+func (r *result) diagnostics() []gofileparser.GFPDiagnostic {
+	var diagnostics []gofileparser.GFPDiagnostic
+	for _, file := range r.allFiles() {
+		diagnostics = append(diagnostics, file.Diagnostics...)
+	}
+	return diagnostics
+}
+
+// load parses the targets of a command line.
+func load(targets []string, cfg *config) (*result, error) {
+	opts := gofileparser.GFPOptions{
+		Tolerant:     cfg.tolerant,
+		Filter:       cfg.selects,
+		IncludeTests: cfg.tests,
+	}
+	if cfg.cacheDir != "" {
+		opts.Cache = gofileparser.NewCache(cfg.cacheDir)
+	}
+	if cfg.tags != "" {
+		buildCtx := build.Default
+		buildCtx.BuildTags = strings.Split(cfg.tags, ",")
+		opts.BuildContext = &buildCtx
+	}
+	parser := gofileparser.NewParser(opts)
+
+	res := &result{}
+	for _, target := range targets {
+		if dir, ok := strings.CutSuffix(target, "..."); ok {
+			dir = filepath.Clean(strings.TrimSuffix(dir, "/"))
+			if dir == "" {
+				dir = "."
+			}
+			pkgs, err := loadTree(parser, dir)
+			if err != nil {
+				return nil, err
+			}
+			res.pkgs = append(res.pkgs, pkgs...)
+			continue
+		}
+
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			pkg, err := parser.ParsePackage(target)
+			if err != nil {
+				return nil, err
+			}
+			res.pkgs = append(res.pkgs, pkg)
+			continue
+		}
+		file, err := parser.ParseFile(target)
+		if err != nil {
+			return nil, err
+		}
+		res.files = append(res.files, file)
+	}
+
+	if len(res.files) > 0 && len(res.pkgs) > 0 {
+		return nil, &usageError{msg: "cannot mix files and package directories"}
+	}
+	return res, nil
+}
+
+// loadTree parses every package in the directory tree below dir with ParseModuleTree, so
+// that the same directories are skipped as by the library and nothing outside dir is
+// parsed.
+func loadTree(parser *gofileparser.GFPParser, dir string) ([]*gofileparser.GFPPackage, error) {
+	module, err := parser.ParseModuleTree(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(module.Packages) == 0 {
+		return nil, fmt.Errorf("no Go packages in %s", dir)
+	}
+	return module.SortedPackages(), nil
+}
@@ -0,0 +1,217 @@
+// Command gofileparser inspects Go source files, packages and modules.
+//
+// Usage:
+//
+//	gofileparser <command> [flags] [file.go | dir | dir/...]...
+//
+// The commands are:
+//
+//	parse     print the full parse result
+//	symbols   list constants, variables, types, functions and methods
+//	imports   list the imports of every file
+//	doc       print the package documentation and the docs of exported symbols
+//
+// Targets default to the current directory. A "dir/..." target selects every package
+// below dir in the module containing it, skipping testdata, vendor, dot and underscore
+// directories and nested modules like the go tool does.
+//
+// Exit codes: 0 on success, 1 if a target could not be read or parsed (or had
+// diagnostics in --tolerant mode), 2 on invalid usage.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exit codes of the command.
+const (
+	exitOK         = 0 // Success
+	exitParseError = 1 // A target could not be read or parsed
+	exitUsage      = 2 // Invalid command line
+)
+
+// usage is printed for invalid command lines and -h.
+const usage = `usage: gofileparser <command> [flags] [file.go | dir | dir/...]...
+
+commands:
+  parse     print the full parse result
+  symbols   list constants, variables, types, functions and methods
+  imports   list the imports of every file
+  doc       print the package documentation and the docs of exported symbols
+
+Run "gofileparser <command> -h" for the flags.
+`
+
+// commands maps each subcommand name to its implementation.
+var commands = map[string]func(w io.Writer, res *result, cfg *config) error{
+	"parse":   writeParse,
+	"symbols": writeSymbols,
+	"imports": writeImports,
+	"doc":     writeDoc,
+}
+
+// config holds the flags shared by all commands.
+type config struct {
+	format       string   // Output format: json, yaml or text
+	exportedOnly bool     // Only report exported symbols
+	noBodies     bool     // Leave out function and method bodies
+	noContent    bool     // Leave out file contents
+	tolerant     bool     // Parse in error-tolerant mode
+	cacheDir     string   // Directory of the parse cache, empty for no cache
+	tags         string   // Comma-separated build tags to select files with
+	tests        bool     // Also parse the _test.go files of package directories
+	include      globList // Only parse files matching one of these globs
+	exclude      globList // Skip files matching one of these globs
+}
+
+// globList is a repeatable flag collecting file name globs.
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(pattern string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	*g = append(*g, pattern)
+	return nil
+}
+
+// match reports whether one of the globs matches the base name or the slash-separated path of file.
+func (g globList) match(file string) bool {
+	for _, pattern := range g {
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.ToSlash(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether a file passes the include and exclude filters.
+func (c *config) selects(file string) bool {
+	if len(c.include) > 0 && !c.include.match(file) {
+		return false
+	}
+	return !c.exclude.match(file)
+}
+
+// usageError is returned for invalid command lines.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "gofileparser: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	cfg, targets, err := parseFlags(args[0], args[1:], stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gofileparser: %v\n", err)
+		return exitUsage
+	}
+
+	res, err := load(targets, cfg)
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(stderr, "gofileparser: %v\n", err)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gofileparser: %v\n", err)
+		return exitParseError
+	}
+
+	if err := command(stdout, res, cfg); err != nil {
+		fmt.Fprintf(stderr, "gofileparser: %v\n", err)
+		return exitParseError
+	}
+
+	if diagnostics := res.diagnostics(); len(diagnostics) > 0 {
+		for _, d := range diagnostics {
+			fmt.Fprintf(stderr, "%s:%d:%d: %s\n", d.File, d.Line, d.Column, d.Message)
+		}
+		return exitParseError
+	}
+	return exitOK
+}
+
+// parseFlags parses the flags of a command. Flags and targets may be interleaved.
+func parseFlags(name string, args []string, stderr io.Writer) (*config, []string, error) {
+	cfg := &config{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gofileparser %s [flags] [file.go | dir | dir/...]...\n\nflags:\n", name)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cfg.format, "format", "text", "output `format`: json, yaml or text")
+	flags.BoolVar(&cfg.exportedOnly, "exported-only", false, "only report exported symbols")
+	flags.BoolVar(&cfg.noBodies, "no-bodies", false, "leave out function and method bodies")
+	flags.BoolVar(&cfg.noContent, "no-content", false, "leave out file contents")
+	flags.BoolVar(&cfg.tolerant, "tolerant", false, "report syntax errors as diagnostics and keep partial results")
+	flags.StringVar(&cfg.cacheDir, "cache", "", "cache parse results in `dir` and reuse them for unchanged files")
+	flags.StringVar(&cfg.tags, "tags", "", "comma-separated build `tags` to satisfy when selecting package files")
+	flags.BoolVar(&cfg.tests, "tests", false, "also parse the _test.go files of package directories")
+	flags.Var(&cfg.include, "include", "only parse files matching `glob` (repeatable)")
+	flags.Var(&cfg.exclude, "exclude", "skip files matching `glob` (repeatable)")
+
+	var targets []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, nil, err
+			}
+			// The flag package has already printed the error and the usage.
+			return nil, nil, errors.New("invalid flags")
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		targets = append(targets, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	switch cfg.format {
+	case "json", "yaml", "text":
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q (want json, yaml or text)", cfg.format)
+	}
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+	return cfg, targets, nil
+}
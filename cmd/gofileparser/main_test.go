@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tealwp/gofileparser"
+	"gopkg.in/yaml.v3"
+)
+
+// writeTree creates files below a temporary directory and returns the directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+// runCommand runs the command line and returns the exit code, stdout and stderr.
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+const shapesSource = `// Package shapes computes areas.
+package shapes
+
+import (
+	"fmt"
+	m "math"
+)
+
+// Pi is a rough approximation.
+const Pi = 3
+
+// Square is a square.
+// Its sides are equal.
+type Square struct {
+	Side  int
+	label string
+}
+
+// Area reports the area.
+func (s *Square) Area() int {
+	return s.Side * s.Side
+}
+
+func (s *Square) name() string {
+	return fmt.Sprint(m.Abs(1))
+}
+
+func helper(values ...int) (n int, err error) {
+	return 0, nil
+}
+`
+
+func TestRunSymbols(t *testing.T) {
+	root := writeTree(t, map[string]string{"shapes/shapes.go": shapesSource})
+	file := filepath.Join(root, "shapes", "shapes.go")
+
+	code, stdout, stderr := runCommand("symbols", filepath.Join(root, "shapes"))
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	expected := []string{
+		file + ":10: const Pi = 3",
+		file + ":14: type Square struct",
+		file + ":28: func helper(values ...int) (n int, err error)",
+		file + ":20: func (*Square) Area() int",
+		file + ":24: func (*Square) name() string",
+	}
+	if stdout != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Unexpected symbols:\n%s", stdout)
+	}
+
+	code, stdout, _ = runCommand("symbols", "--exported-only", "--format=json", filepath.Join(root, "shapes"))
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	var out struct {
+		SchemaVersion int      `json:"schemaVersion"`
+		Symbols       []symbol `json:"symbols"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	var names []string
+	for _, s := range out.Symbols {
+		names = append(names, s.Name)
+	}
+	if out.SchemaVersion != gofileparser.SchemaVersion || strings.Join(names, ",") != "Pi,Square,Area" {
+		t.Errorf("Unexpected exported symbols: %+v", out)
+	}
+}
+
+func TestRunParse(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"go.mod":                    "module example.com/shapes\n",
+		"shapes.go":                 shapesSource,
+		"shapes_test.go":            "package shapes\n",
+		"gen/gen.go":                "package gen\n\nfunc Generated() {}\n",
+		"gen/gen_ignored.go":        "package gen\n\nfunc Ignored() {}\n",
+		"testdata/broken/broken.go": "package broken\n\nfunc {\n",
+	})
+
+	code, stdout, stderr := runCommand("parse", "--format=json", "--no-bodies", "--no-content", "--exclude=*_ignored.go", root+"/...")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	var doc gofileparser.GFPDocument
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("Output is not a GFPDocument: %v", err)
+	}
+	if len(doc.Packages) != 2 {
+		t.Fatalf("Expected 2 packages, got %d", len(doc.Packages))
+	}
+	if doc.Packages[0].ImportPath != "example.com/shapes" || doc.Packages[1].ImportPath != "example.com/shapes/gen" {
+		t.Errorf("Unexpected packages %s, %s", doc.Packages[0].ImportPath, doc.Packages[1].ImportPath)
+	}
+	if len(doc.Packages[1].Functions) != 1 || doc.Packages[1].Functions[0].Name != "Generated" {
+		t.Errorf("Expected the excluded file to be skipped, got %+v", doc.Packages[1].Functions)
+	}
+	if doc.Packages[0].Files[0].Content != "" || doc.Packages[0].Methods[0].Body != "" {
+		t.Errorf("Expected content and bodies to be omitted")
+	}
+
+	code, stdout, stderr = runCommand("parse", "--format=json", filepath.Join(root, "gen")+"/...")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	doc = gofileparser.GFPDocument{}
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("Output is not a GFPDocument: %v", err)
+	}
+	if doc.Package == nil || doc.Package.ImportPath != "example.com/shapes/gen" {
+		t.Errorf("Expected only the package below gen, got %+v", doc)
+	}
+
+	code, stdout, _ = runCommand("parse", "--format=yaml", "--exported-only", filepath.Join(root, "shapes.go"))
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	var out struct {
+		SchemaVersion int `yaml:"schemaVersion"`
+		File          struct {
+			Package string `yaml:"package"`
+			Types   []struct {
+				Fields []struct {
+					Name string `yaml:"name"`
+				} `yaml:"fields"`
+			} `yaml:"types"`
+			Methods []struct {
+				Name string `yaml:"name"`
+			} `yaml:"methods"`
+		} `yaml:"file"`
+	}
+	if err := yaml.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("Output is not valid YAML: %v", err)
+	}
+	if out.SchemaVersion != gofileparser.SchemaVersion || out.File.Package != "shapes" {
+		t.Errorf("Unexpected YAML document: %+v", out)
+	}
+	if len(out.File.Types) != 1 || len(out.File.Types[0].Fields) != 1 || len(out.File.Methods) != 1 {
+		t.Errorf("Expected only exported fields and methods, got %+v", out.File)
+	}
+}
+
+func TestRunImports(t *testing.T) {
+	root := writeTree(t, map[string]string{"shapes.go": shapesSource})
+	file := filepath.Join(root, "shapes.go")
+
+	code, stdout, _ := runCommand("imports", file)
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	expected := file + ":5: \"fmt\"\n" + file + ":6: m \"math\"\n"
+	if stdout != expected {
+		t.Errorf("Unexpected imports:\n%s", stdout)
+	}
+}
+
+func TestRunDoc(t *testing.T) {
+	root := writeTree(t, map[string]string{"shapes.go": shapesSource})
+
+	code, stdout, _ := runCommand("doc", root)
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	expected := `package shapes
+
+Package shapes computes areas.
+
+const Pi = 3
+    Pi is a rough approximation.
+
+type Square struct
+    Square is a square.
+    Its sides are equal.
+
+func (*Square) Area() int
+    Area reports the area.
+`
+	if stdout != expected {
+		t.Errorf("Unexpected doc output:\n%s", stdout)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"go.mod":          "module example.com/ok\n",
+		"ok.go":           "package ok\n",
+		"good/good.go":    "package good\n",
+		"broken/bad.go":   "//go:build !ok\n\npackage broken\n\nfunc F() {\n\tx := \n}\n",
+		"broken/ok.go":    "//go:build ok\n\npackage broken\n",
+		"tests/a_test.go": "package a_test\n",
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, exitUsage},
+		{"unknown command", []string{"list"}, exitUsage},
+		{"unknown flag", []string{"symbols", "--verbose", root}, exitUsage},
+		{"unknown format", []string{"symbols", "--format=xml", root}, exitUsage},
+		{"mixed targets", []string{"symbols", filepath.Join(root, "ok.go"), root}, exitUsage},
+		{"help", []string{"symbols", "-h"}, exitOK},
+		{"missing target", []string{"symbols", filepath.Join(root, "missing.go")}, exitParseError},
+		{"syntax error", []string{"symbols", filepath.Join(root, "broken")}, exitParseError},
+		{"tolerant syntax error", []string{"symbols", "--tolerant", filepath.Join(root, "broken")}, exitParseError},
+		{"flags after targets", []string{"symbols", root, "--format=json"}, exitOK},
+		{"cache", []string{"symbols", "--cache", filepath.Join(t.TempDir(), "cache"), root}, exitOK},
+		{"tags exclude broken file", []string{"symbols", "--tags=ok", filepath.Join(root, "broken")}, exitOK},
+		{"only test files", []string{"symbols", filepath.Join(root, "tests")}, exitParseError},
+		{"include tests", []string{"symbols", "--tests", filepath.Join(root, "tests")}, exitOK},
+		{"tree with syntax error", []string{"symbols", root + "/..."}, exitParseError},
+		{"tree beside syntax error", []string{"symbols", filepath.Join(root, "good") + "/..."}, exitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := runCommand(tt.args...)
+			if code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+
+	_, stdout, stderr := runCommand("symbols", "--tolerant", filepath.Join(root, "broken"))
+	if !strings.Contains(stdout, "func F()") || !strings.Contains(stderr, "bad.go:7:1:") {
+		t.Errorf("Expected partial results and diagnostics, got %q and %q", stdout, stderr)
+	}
+}
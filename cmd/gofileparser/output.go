@@ -0,0 +1,460 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tealwp/gofileparser"
+	"gopkg.in/yaml.v3"
+)
+
+// symbol is a single declaration as reported by the symbols and doc commands.
+type symbol struct {
+	Kind      string `json:"kind"`                // const, var, type, interface, func or method
+	Name      string `json:"name"`                // Name of the declaration
+	Receiver  string `json:"receiver,omitempty"`  // Receiver type of a method
+	Signature string `json:"signature,omitempty"` // Signature of a function, definition of a type, type and value of a constant or variable
+	File      string `json:"file"`                // File declaring the symbol
+	Line      int    `json:"line"`                // Line of the declaration
+	Exported  bool   `json:"exported"`            // Whether the symbol is exported
+	Doc       string `json:"doc,omitempty"`       // Documentation comment
+}
+
+// importEntry is a single import as reported by the imports command.
+type importEntry struct {
+	File string `json:"file"`           // File declaring the import
+	Path string `json:"path"`           // Import path
+	Name string `json:"name,omitempty"` // Local name, if any
+	Line int    `json:"line"`           // Line of the import
+}
+
+// packageDoc is the documentation of one package as reported by the doc command.
+type packageDoc struct {
+	Name       string   `json:"name"`                 // Package name
+	ImportPath string   `json:"importPath,omitempty"` // Import path, if known
+	Doc        string   `json:"doc,omitempty"`        // Package documentation
+	Symbols    []symbol `json:"symbols,omitempty"`    // Exported symbols in declaration order
+}
+
+// writeParse writes the full parse result.
+func writeParse(w io.Writer, res *result, cfg *config) error {
+	if cfg.exportedOnly {
+		res = exportedResult(res)
+	}
+	if cfg.format == "text" {
+		return writeParseText(w, res, cfg)
+	}
+
+	var v any
+	switch {
+	case len(res.pkgs) == 1:
+		v = res.pkgs[0]
+	case len(res.pkgs) > 1:
+		v = res.pkgs
+	case len(res.files) == 1:
+		v = res.files[0]
+	default:
+		v = res.files
+	}
+	data, err := gofileparser.ToJSON(v, gofileparser.GFPJSONOptions{
+		Indent:      "  ",
+		OmitContent: cfg.noContent,
+		OmitBodies:  cfg.noBodies,
+	})
+	if err != nil {
+		return err
+	}
+	return writeData(w, data, cfg.format)
+}
+
+// writeParseText writes the declarations of every file, grouped by package.
+func writeParseText(w io.Writer, res *result, cfg *config) error {
+	writeFiles := func(files []*gofileparser.GFPGoFile) {
+		for _, file := range files {
+			fmt.Fprintf(w, "// %s\n", file.FileName)
+			for _, s := range fileSymbols(file) {
+				fmt.Fprintf(w, "%s\n", fullDeclaration(file, s, cfg.noBodies))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, pkg := range res.pkgs {
+		fmt.Fprintf(w, "%s\n\n", packageClause(pkg.Name, pkg.ImportPath))
+		writeFiles(pkg.Files)
+	}
+	writeFiles(res.files)
+	return nil
+}
+
+// writeSymbols writes the symbols of every file.
+func writeSymbols(w io.Writer, res *result, cfg *config) error {
+	var symbols []symbol
+	for _, file := range res.allFiles() {
+		for _, s := range fileSymbols(file) {
+			if s.Exported || !cfg.exportedOnly {
+				symbols = append(symbols, s)
+			}
+		}
+	}
+
+	if cfg.format == "text" {
+		for _, s := range symbols {
+			fmt.Fprintf(w, "%s:%d: %s\n", s.File, s.Line, declaration(s))
+		}
+		return nil
+	}
+	return writeValue(w, struct {
+		SchemaVersion int      `json:"schemaVersion"`
+		Symbols       []symbol `json:"symbols"`
+	}{gofileparser.SchemaVersion, symbols}, cfg.format)
+}
+
+// writeImports writes the imports of every file.
+func writeImports(w io.Writer, res *result, cfg *config) error {
+	var imports []importEntry
+	for _, file := range res.allFiles() {
+		for _, imp := range file.Imports {
+			// GFPImport.Path keeps the quotes of the import spec.
+			importPath, err := strconv.Unquote(imp.Path)
+			if err != nil {
+				importPath = imp.Path
+			}
+			imports = append(imports, importEntry{File: file.FileName, Path: importPath, Name: imp.Name, Line: imp.Line})
+		}
+	}
+
+	if cfg.format == "text" {
+		for _, imp := range imports {
+			if imp.Name != "" {
+				fmt.Fprintf(w, "%s:%d: %s %q\n", imp.File, imp.Line, imp.Name, imp.Path)
+			} else {
+				fmt.Fprintf(w, "%s:%d: %q\n", imp.File, imp.Line, imp.Path)
+			}
+		}
+		return nil
+	}
+	return writeValue(w, struct {
+		SchemaVersion int           `json:"schemaVersion"`
+		Imports       []importEntry `json:"imports"`
+	}{gofileparser.SchemaVersion, imports}, cfg.format)
+}
+
+// writeDoc writes the documentation of every package and its exported symbols.
+// Files given directly are documented as the package they declare.
+func writeDoc(w io.Writer, res *result, cfg *config) error {
+	var docs []packageDoc
+	for _, pkg := range res.pkgs {
+		docs = append(docs, newPackageDoc(pkg.Name, pkg.ImportPath, pkg.Doc, pkg.Files))
+	}
+	for _, file := range res.files {
+		docs = append(docs, newPackageDoc(file.Package, "", file.FileDoc, []*gofileparser.GFPGoFile{file}))
+	}
+
+	if cfg.format == "text" {
+		for i, doc := range docs {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "%s\n", packageClause(doc.Name, doc.ImportPath))
+			if doc.Doc != "" {
+				fmt.Fprintf(w, "\n%s", indent(doc.Doc, ""))
+			}
+			for _, s := range doc.Symbols {
+				fmt.Fprintf(w, "\n%s\n", declaration(s))
+				if s.Doc != "" {
+					fmt.Fprint(w, indent(s.Doc, "    "))
+				}
+			}
+		}
+		return nil
+	}
+	return writeValue(w, struct {
+		SchemaVersion int          `json:"schemaVersion"`
+		Packages      []packageDoc `json:"packages"`
+	}{gofileparser.SchemaVersion, docs}, cfg.format)
+}
+
+// newPackageDoc collects the exported symbols of files.
+func newPackageDoc(name, importPath, doc string, files []*gofileparser.GFPGoFile) packageDoc {
+	pkgDoc := packageDoc{Name: name, ImportPath: importPath, Doc: doc}
+	for _, file := range files {
+		for _, s := range fileSymbols(file) {
+			if s.Exported {
+				pkgDoc.Symbols = append(pkgDoc.Symbols, s)
+			}
+		}
+	}
+	return pkgDoc
+}
+
+// fileSymbols lists the declarations of a file, grouped by kind in declaration order.
+func fileSymbols(file *gofileparser.GFPGoFile) []symbol {
+	var symbols []symbol
+	add := func(kind, name, receiver, signature string, line int, doc string) {
+		exported := token.IsExported(name)
+		if receiver != "" {
+			exported = exported && token.IsExported(receiverBase(receiver))
+		}
+		symbols = append(symbols, symbol{
+			Kind:      kind,
+			Name:      name,
+			Receiver:  receiver,
+			Signature: signature,
+			File:      file.FileName,
+			Line:      line,
+			Exported:  exported,
+			Doc:       doc,
+		})
+	}
+
+	for _, c := range file.Constants {
+		add("const", c.Name, "", valueSignature(c.Type, c.Value), c.Line, c.Doc)
+	}
+	for _, v := range file.Variables {
+		add("var", v.Name, "", valueSignature(v.Type, v.Value), v.Line, v.Doc)
+	}
+	for _, t := range file.Types {
+		add("type", t.Name, "", typeParams(t.TypeParams)+" "+typeSignature(t.Def), t.Line, t.Doc)
+	}
+	for _, i := range file.Interfaces {
+		add("interface", i.Name, "", typeParams(i.TypeParams)+" interface", i.Line, i.Doc)
+	}
+	for _, f := range file.Functions {
+		add("func", f.Name, "", typeParams(f.TypeParams)+funcSignature(f.Parameters, f.Results), f.Line, f.Doc)
+	}
+	for _, m := range file.Methods {
+		add("method", m.Name, m.Receiver, funcSignature(m.Parameters, m.Results), m.Line, m.Doc)
+	}
+	return symbols
+}
+
+// fullDeclaration renders a symbol of file with the complete type definition and,
+// unless noBodies is set, the function or method body.
+func fullDeclaration(file *gofileparser.GFPGoFile, s symbol, noBodies bool) string {
+	switch s.Kind {
+	case "type":
+		for _, t := range file.Types {
+			if t.Name == s.Name && t.Line == s.Line {
+				return "type " + t.Name + typeParams(t.TypeParams) + " " + t.Def
+			}
+		}
+	case "func":
+		for _, f := range file.Functions {
+			if f.Name == s.Name && f.Line == s.Line && !noBodies && f.Body != "" {
+				return declaration(s) + " " + f.Body
+			}
+		}
+	case "method":
+		for _, m := range file.Methods {
+			if m.Name == s.Name && m.Line == s.Line && !noBodies && m.Body != "" {
+				return declaration(s) + " " + m.Body
+			}
+		}
+	}
+	return declaration(s)
+}
+
+// declaration renders a symbol the way it is declared in Go source.
+func declaration(s symbol) string {
+	switch s.Kind {
+	case "func":
+		return "func " + s.Name + s.Signature
+	case "method":
+		return "func (" + s.Receiver + ") " + s.Name + s.Signature
+	case "type", "interface":
+		return "type " + s.Name + s.Signature
+	}
+	return strings.TrimSpace(s.Kind + " " + s.Name + " " + s.Signature)
+}
+
+// valueSignature renders the type and value of a constant or variable as in "int = 3".
+func valueSignature(typ, value string) string {
+	switch {
+	case typ != "" && value != "":
+		return typ + " = " + value
+	case value != "":
+		return "= " + value
+	}
+	return typ
+}
+
+// typeSignature shortens a struct or interface definition to its keyword.
+func typeSignature(def string) string {
+	for _, keyword := range []string{"struct", "interface"} {
+		if strings.HasPrefix(def, keyword) {
+			return keyword
+		}
+	}
+	return def
+}
+
+// typeParams renders a type parameter list such as "[K comparable, V any]".
+func typeParams(params []gofileparser.GFPTypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+	list := make([]string, len(params))
+	for i, param := range params {
+		list[i] = strings.TrimSpace(param.Name + " " + param.Constraint)
+	}
+	return "[" + strings.Join(list, ", ") + "]"
+}
+
+// funcSignature renders parameters and results as in "(name string, args ...any) error".
+func funcSignature(params, results []gofileparser.GFPParameter) string {
+	s := "(" + parameterList(params) + ")"
+	switch {
+	case len(results) == 1 && results[0].Name == "":
+		s += " " + results[0].Type
+	case len(results) > 0:
+		s += " (" + parameterList(results) + ")"
+	}
+	return s
+}
+
+// parameterList renders a comma-separated parameter list.
+func parameterList(params []gofileparser.GFPParameter) string {
+	list := make([]string, len(params))
+	for i, param := range params {
+		typ := param.Type
+		if param.Variadic {
+			typ = "..." + typ
+		}
+		list[i] = strings.TrimSpace(param.Name + " " + typ)
+	}
+	return strings.Join(list, ", ")
+}
+
+// receiverBase returns the type name of a receiver such as "*List[T]".
+func receiverBase(receiver string) string {
+	name := strings.TrimPrefix(receiver, "*")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// packageClause renders a package clause with its import comment.
+func packageClause(name, importPath string) string {
+	if importPath == "" {
+		return "package " + name
+	}
+	return fmt.Sprintf("package %s // import %q", name, importPath)
+}
+
+// indent prefixes every line of text with prefix.
+func indent(text, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line != "" {
+			b.WriteString(prefix + line)
+		}
+	}
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeValue encodes v in format, which must be json or yaml.
+func writeValue(w io.Writer, v any, format string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeData(w, data, format)
+}
+
+// writeData writes JSON data as is or converted to YAML. The conversion goes through
+// the JSON encoding so that both formats use the same field names.
+func writeData(w io.Writer, data []byte, format string) error {
+	if format == "yaml" {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// exportedResult returns a copy of res with unexported declarations removed.
+func exportedResult(res *result) *result {
+	exported := &result{}
+	for _, file := range res.files {
+		exported.files = append(exported.files, exportedFile(file))
+	}
+	for _, pkg := range res.pkgs {
+		copied := *pkg
+		copied.Files = nil
+		for _, file := range pkg.Files {
+			copied.Files = append(copied.Files, exportedFile(file))
+		}
+		copied.Constants = filter(pkg.Constants, func(c gofileparser.GFPConstant) bool { return token.IsExported(c.Name) })
+		copied.Variables = filter(pkg.Variables, func(v gofileparser.GFPVariable) bool { return token.IsExported(v.Name) })
+		copied.Types = exportedTypes(pkg.Types)
+		copied.Functions = filter(pkg.Functions, func(f gofileparser.GFPFunction) bool { return token.IsExported(f.Name) })
+		copied.Methods = filter(pkg.Methods, exportedMethod)
+		copied.Interfaces = exportedInterfaces(pkg.Interfaces)
+		exported.pkgs = append(exported.pkgs, &copied)
+	}
+	return exported
+}
+
+// exportedFile returns a copy of file with unexported declarations removed.
+func exportedFile(file *gofileparser.GFPGoFile) *gofileparser.GFPGoFile {
+	copied := *file
+	copied.Constants = filter(file.Constants, func(c gofileparser.GFPConstant) bool { return token.IsExported(c.Name) })
+	copied.Variables = filter(file.Variables, func(v gofileparser.GFPVariable) bool { return token.IsExported(v.Name) })
+	copied.Types = exportedTypes(file.Types)
+	copied.Functions = filter(file.Functions, func(f gofileparser.GFPFunction) bool { return token.IsExported(f.Name) })
+	copied.Methods = filter(file.Methods, exportedMethod)
+	copied.Interfaces = exportedInterfaces(file.Interfaces)
+	return &copied
+}
+
+// exportedTypes returns the exported types with only their exported fields and methods.
+func exportedTypes(types []gofileparser.GFPType) []gofileparser.GFPType {
+	types = filter(types, func(t gofileparser.GFPType) bool { return token.IsExported(t.Name) })
+	for i := range types {
+		types[i].Fields = filter(types[i].Fields, func(f gofileparser.GFPField) bool { return f.Exported })
+		types[i].Methods = filter(types[i].Methods, exportedMethod)
+	}
+	return types
+}
+
+// exportedInterfaces returns the exported interfaces with only their exported methods.
+func exportedInterfaces(ifaces []gofileparser.GFPInterface) []gofileparser.GFPInterface {
+	ifaces = filter(ifaces, func(i gofileparser.GFPInterface) bool { return token.IsExported(i.Name) })
+	for i := range ifaces {
+		ifaces[i].Methods = filter(ifaces[i].Methods, func(m gofileparser.GFPInterfaceMethod) bool { return token.IsExported(m.Name) })
+	}
+	return ifaces
+}
+
+// exportedMethod reports whether a method is exported on an exported type.
+func exportedMethod(m gofileparser.GFPMethod) bool {
+	return token.IsExported(m.Name) && token.IsExported(receiverBase(m.Receiver))
+}
+
+// filter returns a new slice with the elements of items for which keep reports true.
+func filter[T any](items []T, keep func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,423 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// maxShift is the largest shift count evaluated, to keep huge shifts from exhausting memory.
+const maxShift = 1 << 12
+
+// constantSpec is a constant of a file together with what is needed to evaluate it.
+type constantSpec struct {
+	constant *GFPConstant
+	expr     ast.Expr // Value expression, repeated from an earlier spec if implicit
+	typ      ast.Expr // Declared type, repeated from an earlier spec if implicit
+	iota     int      // Index of the spec in its declaration
+}
+
+// constantEvaluator evaluates the constant expressions of a file or package with go/constant.
+// Without type information, names can only be resolved to constants of the same package,
+// and operations are carried out on untyped values, except that conversions to
+// predeclared and locally declared basic types are applied and that the bitwise
+// complement of an unsigned value is taken within the size of its type.
+type constantEvaluator struct {
+	types  map[string]string         // Definitions of the types declared in the files
+	values map[string]constant.Value // Constants evaluated so far, by name
+	typed  map[string]*types.Basic   // Basic types of the typed constants evaluated so far, Invalid if not basic or unknown
+}
+
+// constantSizes are the sizes of basic types assumed without type information, the same
+// go/types assumes when type checking.
+var constantSizes = types.SizesFor("gc", "amd64")
+
+// evaluateConstants sets the exact value and kind of the constants of a file whose value
+// can be derived from the file alone. Constants may refer to constants declared later in
+// the file.
+func evaluateConstants(file *ast.File, goFile *GFPGoFile) {
+	evaluateFileConstants([]*ast.File{file}, []*GFPGoFile{goFile})
+}
+
+// evaluatePackageConstants evaluates the constants of the files of a package together, so
+// that constants may refer to constants and types declared in other files. Constants that
+// already have a value keep it. Files of the external test package are left out. The files
+// are parsed again from their content, since cached files come without their AST, so this
+// is only done if a constant without a value refers to a name declared in another file.
+func evaluatePackageConstants(goFiles []*GFPGoFile, pkgName string) {
+	var pkgFiles []*GFPGoFile
+	for _, goFile := range goFiles {
+		if goFile.Package == pkgName {
+			pkgFiles = append(pkgFiles, goFile)
+		}
+	}
+	if !refersToOtherFiles(pkgFiles) {
+		return
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	var parsed []*GFPGoFile
+	for _, goFile := range pkgFiles {
+		file, err := parser.ParseFile(fset, goFile.FileName, goFile.Content, parser.SkipObjectResolution)
+		if err != nil {
+			// Files with syntax errors may have been parsed differently in tolerant mode.
+			continue
+		}
+		files = append(files, file)
+		parsed = append(parsed, goFile)
+	}
+	evaluateFileConstants(files, parsed)
+}
+
+// refersToOtherFiles reports whether a constant of goFiles without a value refers to a
+// constant or type declared in another of the files. Constants that repeat the values of
+// an earlier spec implicitly need not be looked at, since that spec has no value either.
+func refersToOtherFiles(goFiles []*GFPGoFile) bool {
+	declared := make(map[string]int) // Index of the declaring file by name
+	for i, goFile := range goFiles {
+		for _, c := range goFile.Constants {
+			declared[c.Name] = i
+		}
+		for _, t := range goFile.Types {
+			declared[t.Name] = i
+		}
+	}
+	for i, goFile := range goFiles {
+		for _, c := range goFile.Constants {
+			if c.ExactValue != "" {
+				continue
+			}
+			for _, src := range []string{c.Type, c.Value} {
+				for _, name := range typeIdents(src) {
+					if j, ok := declared[name]; ok && j != i && name != "_" {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// evaluateFileConstants evaluates the constants of files, which are the ASTs of goFiles,
+// in one scope, and sets the values of those that do not have one yet.
+func evaluateFileConstants(files []*ast.File, goFiles []*GFPGoFile) {
+	e := &constantEvaluator{
+		types:  make(map[string]string),
+		values: make(map[string]constant.Value),
+		typed:  make(map[string]*types.Basic),
+	}
+	var specs []constantSpec
+	for k, file := range files {
+		goFile := goFiles[k]
+		for _, t := range goFile.Types {
+			e.types[t.Name] = t.Def
+		}
+		specs = append(specs, fileConstantSpecs(file, goFile)...)
+	}
+
+	// Evaluate until no further constant can be resolved, so that the order of the
+	// declarations does not matter.
+	done := make([]bool, len(specs))
+	for progress := true; progress; {
+		progress = false
+		for i, spec := range specs {
+			if spec.expr == nil || done[i] {
+				continue
+			}
+			value := e.convert(e.eval(spec.expr, spec.iota), spec.typ)
+			if value.Kind() == constant.Unknown {
+				continue
+			}
+			if spec.constant.Name != "_" {
+				e.values[spec.constant.Name] = value
+				if spec.typ != nil {
+					e.typed[spec.constant.Name] = e.typeOf(spec.typ)
+				} else {
+					e.typed[spec.constant.Name] = e.exprType(spec.expr)
+				}
+			}
+			if spec.constant.ExactValue == "" {
+				setConstantValue(spec.constant, value)
+			}
+			done[i] = true
+			progress = true
+		}
+	}
+}
+
+// fileConstantSpecs returns the constants of a file together with their expressions.
+func fileConstantSpecs(file *ast.File, goFile *GFPGoFile) []constantSpec {
+	var specs []constantSpec
+	i := 0
+	for _, decl := range file.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.CONST {
+			continue
+		}
+		var exprs []ast.Expr
+		var typ ast.Expr
+		for iota, spec := range d.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			if len(vs.Values) > 0 {
+				exprs, typ = vs.Values, vs.Type
+			}
+			for j := range vs.Names {
+				c := constantSpec{constant: &goFile.Constants[i], typ: typ, iota: iota}
+				if j < len(exprs) {
+					c.expr = exprs[j]
+				}
+				specs = append(specs, c)
+				i++
+			}
+		}
+	}
+	return specs
+}
+
+// setConstantValue records the exact value and kind of value in c.
+func setConstantValue(c *GFPConstant, value constant.Value) {
+	if value == nil || value.Kind() == constant.Unknown {
+		return
+	}
+	c.ExactValue = value.ExactString()
+	c.Kind = strings.ToLower(value.Kind().String())
+}
+
+// eval evaluates a constant expression, returning an unknown value if it cannot.
+func (e *constantEvaluator) eval(expr ast.Expr, iota int) constant.Value {
+	unknown := constant.MakeUnknown()
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(x.Value, x.Kind, 0)
+	case *ast.Ident:
+		switch x.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true", "false":
+			return constant.MakeBool(x.Name == "true")
+		}
+		if value, ok := e.values[x.Name]; ok {
+			return value
+		}
+	case *ast.ParenExpr:
+		return e.eval(x.X, iota)
+	case *ast.UnaryExpr:
+		value := e.eval(x.X, iota)
+		if value.Kind() == constant.Unknown {
+			return unknown
+		}
+		switch x.Op {
+		case token.ADD, token.SUB, token.XOR, token.NOT:
+			if (x.Op == token.NOT) != (value.Kind() == constant.Bool) {
+				return unknown
+			}
+			// The complement of an unsigned value has as many bits as its type, which
+			// must therefore be known.
+			var prec uint
+			if t := e.exprType(x.X); x.Op == token.XOR && t != nil {
+				if t.Kind() == types.Invalid {
+					return unknown
+				}
+				if t.Info()&types.IsUnsigned != 0 {
+					prec = uint(constantSizes.Sizeof(t) * 8)
+				}
+			}
+			return constant.UnaryOp(x.Op, value, prec)
+		}
+	case *ast.BinaryExpr:
+		return e.binary(x, iota)
+	case *ast.CallExpr:
+		if len(x.Args) != 1 {
+			return unknown
+		}
+		value := e.eval(x.Args[0], iota)
+		switch fn := exprToString(x.Fun); {
+		case fn == "len":
+			if value.Kind() != constant.String {
+				return unknown
+			}
+			return constant.MakeInt64(int64(len(constant.StringVal(value))))
+		case fn == "cap" || fn == "real" || fn == "imag" || strings.HasPrefix(fn, "unsafe."):
+			return unknown
+		}
+		// A conversion such as Color(1) or float64(x).
+		if value.Kind() == constant.Unknown {
+			return unknown
+		}
+		return e.convert(value, x.Fun)
+	}
+	return unknown
+}
+
+// binary evaluates a binary expression.
+func (e *constantEvaluator) binary(x *ast.BinaryExpr, iota int) constant.Value {
+	unknown := constant.MakeUnknown()
+	left, right := e.eval(x.X, iota), e.eval(x.Y, iota)
+	if left.Kind() == constant.Unknown || right.Kind() == constant.Unknown {
+		return unknown
+	}
+
+	switch x.Op {
+	case token.SHL, token.SHR:
+		left, right = constant.ToInt(left), constant.ToInt(right)
+		shift, ok := constant.Uint64Val(right)
+		if left.Kind() != constant.Int || !ok || shift > maxShift {
+			return unknown
+		}
+		return constant.Shift(left, x.Op, uint(shift))
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if !compatible(left, right) {
+			return unknown
+		}
+		return constant.MakeBool(constant.Compare(left, x.Op, right))
+	case token.LAND, token.LOR:
+		if left.Kind() != constant.Bool || right.Kind() != constant.Bool {
+			return unknown
+		}
+		return constant.BinaryOp(left, x.Op, right)
+	}
+
+	if !compatible(left, right) || left.Kind() == constant.Bool {
+		return unknown
+	}
+	op := x.Op
+	switch op {
+	case token.QUO, token.REM:
+		if constant.Sign(right) == 0 {
+			return unknown
+		}
+		// Dividing integers truncates, like integer division at run time.
+		if left.Kind() == constant.Int && right.Kind() == constant.Int && op == token.QUO {
+			op = token.QUO_ASSIGN
+		}
+	case token.ADD:
+		if left.Kind() == constant.String {
+			return constant.BinaryOp(left, op, right)
+		}
+	}
+	if left.Kind() == constant.String {
+		return unknown
+	}
+	if (op == token.REM || op == token.AND || op == token.OR || op == token.XOR || op == token.AND_NOT) &&
+		(left.Kind() != constant.Int || right.Kind() != constant.Int) {
+		return unknown
+	}
+	return constant.BinaryOp(left, op, right)
+}
+
+// compatible reports whether two values can be combined: both strings, both booleans or
+// both numeric.
+func compatible(x, y constant.Value) bool {
+	numeric := func(v constant.Value) bool {
+		return v.Kind() == constant.Int || v.Kind() == constant.Float || v.Kind() == constant.Complex
+	}
+	return x.Kind() == y.Kind() || (numeric(x) && numeric(y))
+}
+
+// exprType returns the basic type of a typed constant expression, or nil if the
+// expression is untyped. Types that are not basic types known to e are Invalid.
+func (e *constantEvaluator) exprType(expr ast.Expr) *types.Basic {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return e.typed[x.Name]
+	case *ast.ParenExpr:
+		return e.exprType(x.X)
+	case *ast.UnaryExpr:
+		return e.exprType(x.X)
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return nil
+		case token.SHL, token.SHR:
+			return e.exprType(x.X)
+		}
+		if t := e.exprType(x.X); t != nil {
+			return t
+		}
+		return e.exprType(x.Y)
+	case *ast.CallExpr:
+		if exprToString(x.Fun) == "len" {
+			return types.Typ[types.Int]
+		}
+		return e.typeOf(x.Fun)
+	}
+	return nil
+}
+
+// typeOf returns the basic type typ denotes, or Invalid if it is not a basic type known to e.
+func (e *constantEvaluator) typeOf(typ ast.Expr) *types.Basic {
+	if t := e.basicType(typ); t != nil {
+		return t
+	}
+	return types.Typ[types.Invalid]
+}
+
+// basicType returns the basic type typ denotes, if it is a predeclared basic type or a
+// type of the files defined as one, or nil.
+func (e *constantEvaluator) basicType(typ ast.Expr) *types.Basic {
+	name := exprToString(typ)
+	for depth := 0; depth < 10; depth++ {
+		def, ok := e.types[name]
+		if !ok {
+			break
+		}
+		name = def
+	}
+
+	obj, ok := types.Universe.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	t, _ := obj.Type().(*types.Basic)
+	return t
+}
+
+// convert converts value to the type typ, if typ is a predeclared basic type or a type of
+// the file defined as one. Values of other types are returned unchanged.
+func (e *constantEvaluator) convert(value constant.Value, typ ast.Expr) constant.Value {
+	if typ == nil || value.Kind() == constant.Unknown {
+		return value
+	}
+	t := e.basicType(typ)
+	if t == nil {
+		return value
+	}
+	info := t.Info()
+	switch {
+	case info&types.IsString != 0 && value.Kind() == constant.Int:
+		// string(rune)
+		code, ok := constant.Int64Val(value)
+		if !ok {
+			return constant.MakeUnknown()
+		}
+		return constant.MakeString(string(rune(code)))
+	case info&types.IsInteger != 0:
+		value = constant.ToInt(value)
+	case info&types.IsFloat != 0:
+		value = constant.ToFloat(value)
+	case info&types.IsComplex != 0:
+		value = constant.ToComplex(value)
+	}
+	return value
+}
+
+// annotateConstants replaces the values of the constants in goFiles with those computed
+// by go/types, which also resolves references to other files and packages.
+func annotateConstants(scope *types.Scope, goFiles []*GFPGoFile) {
+	for _, goFile := range goFiles {
+		for i := range goFile.Constants {
+			c := &goFile.Constants[i]
+			if obj, ok := scope.Lookup(c.Name).(*types.Const); ok {
+				setConstantValue(c, obj.Val())
+			}
+		}
+	}
+}
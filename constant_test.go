@@ -0,0 +1,183 @@
+package gofileparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const constantSource = `package consts
+
+import "math"
+
+type Color int
+
+type Ratio float64
+
+type Flags uint8
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+const (
+	_  = iota
+	KB = 1 << (10 * iota)
+	MB
+)
+
+const (
+	A, B = iota, iota * 10
+	C, D
+)
+
+const (
+	Name     = "gopher"
+	Greeting = "hello, " + Name
+	Length   = len(Greeting)
+	Letter   = string(rune(65))
+	Later    = Earlier + 1
+	Earlier  = 41
+	Half     = Ratio(1) / 2
+	Third    = 1.0 / 3
+	Quotient = 7 / 2
+	Shift    = 1 << 100
+	Flag     = Quotient > 3 && !false
+	Unknown  = math.MaxInt8
+	Zero     = 1 / 0
+)
+
+const (
+	MaxUint        = ^uint(0)
+	MaxByte        = ^uint8(0)
+	All      Flags = ^Flags(0)
+	Low      Flags = 1
+	NotLow         = ^Low
+	Negative       = ^0
+)
+`
+
+func TestEvaluateConstants(t *testing.T) {
+	goFile := mustParseSource(t, "consts.go", constantSource)
+
+	tests := []struct {
+		name  string
+		typ   string
+		exact string
+		kind  string
+	}{
+		{"Red", "Color", "0", "int"},
+		{"Green", "Color", "1", "int"},
+		{"Blue", "Color", "2", "int"},
+		{"_", "", "0", "int"},
+		{"KB", "", "1024", "int"},
+		{"MB", "", "1048576", "int"},
+		{"A", "", "0", "int"},
+		{"B", "", "0", "int"},
+		{"C", "", "1", "int"},
+		{"D", "", "10", "int"},
+		{"Name", "", `"gopher"`, "string"},
+		{"Greeting", "", `"hello, gopher"`, "string"},
+		{"Length", "", "13", "int"},
+		{"Letter", "", `"A"`, "string"},
+		{"Later", "", "42", "int"},
+		{"Earlier", "", "41", "int"},
+		{"Half", "", "1/2", "float"},
+		{"Third", "", "1/3", "float"},
+		{"Quotient", "", "3", "int"},
+		{"Shift", "", "1267650600228229401496703205376", "int"},
+		{"Flag", "", "false", "bool"},
+		{"Unknown", "", "", ""},
+		{"Zero", "", "", ""},
+		{"MaxUint", "", "18446744073709551615", "int"},
+		{"MaxByte", "", "255", "int"},
+		{"All", "Flags", "255", "int"},
+		{"Low", "Flags", "1", "int"},
+		{"NotLow", "", "254", "int"},
+		{"Negative", "", "-1", "int"},
+	}
+
+	if len(goFile.Constants) != len(tests) {
+		t.Fatalf("Expected %d constants, got %d", len(tests), len(goFile.Constants))
+	}
+	for i, tt := range tests {
+		c := goFile.Constants[i]
+		if c.Name != tt.name || c.Type != tt.typ || c.ExactValue != tt.exact || c.Kind != tt.kind {
+			t.Errorf("Expected %s %s = %s (%s), got %s %s = %s (%s)",
+				tt.name, tt.typ, tt.exact, tt.kind, c.Name, c.Type, c.ExactValue, c.Kind)
+		}
+	}
+	if goFile.Constants[1].Value != "" {
+		t.Errorf("Expected the source value of an implicit repetition to stay empty, got %q", goFile.Constants[1].Value)
+	}
+}
+
+func TestEvaluateConstantsTypeCheck(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	createTempGoFile(t, root, "a.go", "package mod\n\nimport \"math\"\n\nconst (\n\tMax = math.MaxInt8\n\tSum = Other + 1\n\tMask = ^uint8(0)\n\tAll = ^Flags(0)\n)\n")
+	createTempGoFile(t, root, "b.go", "package mod\n\ntype Flags uint8\n\nconst Other = 1\n")
+
+	for _, typeCheck := range []bool{false, true} {
+		// The cache hands out files without their AST.
+		opts := GFPOptions{TypeCheck: typeCheck, Cache: NewCache("")}
+		if _, err := NewParser(opts).parseGoPackage(context.Background(), root); err != nil {
+			t.Fatalf("parseGoPackage failed: %v", err)
+		}
+		pkg, err := NewParser(opts).parseGoPackage(context.Background(), root)
+		if err != nil {
+			t.Fatalf("parseGoPackage failed: %v", err)
+		}
+		// Without type information, other packages are unknown.
+		expected := map[string]string{"Max": "", "Sum": "2", "Mask": "255", "All": "255", "Other": "1"}
+		if typeCheck {
+			expected = map[string]string{"Max": "127", "Sum": "2", "Mask": "255", "All": "255", "Other": "1"}
+		}
+		for _, c := range pkg.Constants {
+			if want, ok := expected[c.Name]; ok && c.ExactValue != want {
+				t.Errorf("Type check %v: expected %s = %q, got %q", typeCheck, c.Name, want, c.ExactValue)
+			}
+		}
+	}
+}
+
+func TestRefersToOtherFiles(t *testing.T) {
+	other := mustParseSource(t, "other.go", "package mod\n\ntype Flags uint8\n\nconst Base = 1\n")
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"other package", "package mod\n\nimport \"time\"\n\nconst Timeout = 5 * time.Second\n", false},
+		{"evaluated", "package mod\n\nconst Base2 = 2\n", false},
+		{"constant of other file", "package mod\n\nconst Next = Base + 1\n", true},
+		{"type of other file", "package mod\n\nconst All = ^Flags(0)\n", true},
+	}
+	for _, tt := range tests {
+		goFile := mustParseSource(t, "mod.go", tt.src)
+		if got := refersToOtherFiles([]*GFPGoFile{goFile, other}); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestEvaluateConstantsFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "consts.go")
+	if err := os.WriteFile(path, []byte(constantSource), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	goFile, err := NewParser(GFPOptions{TypeCheck: true}).parseGoFile(path)
+	if err != nil {
+		t.Fatalf("parseGoFile failed: %v", err)
+	}
+	for _, c := range goFile.Constants {
+		if c.Name == "Unknown" && c.ExactValue != "127" {
+			t.Errorf("Expected math.MaxInt8 to be resolved from the standard library, got %q", c.ExactValue)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// toolDirective matches directives of the form "//tool:name", such as "//go:generate"
+	// or "//lint:file-ignore". Like the go tool, no space is allowed after the slashes.
+	toolDirective = regexp.MustCompile(`^//([a-z0-9]+:[a-z0-9_-]+)(?:\s+(.*))?$`)
+	// marker matches "+tool:path" markers as used by kubebuilder and the Kubernetes code
+	// generators, with or without a space after the slashes.
+	marker = regexp.MustCompile(`^//\s?(\+[A-Za-z][\w.-]*(?::[\w.-]+)*)(?:=(.*))?$`)
+	// namedArg matches a named marker argument such as `type="date"`.
+	namedArg = regexp.MustCompile(`^[A-Za-z][\w-]*=`)
+)
+
+// parseDirective parses a single comment as a compiler directive or tool marker.
+// It reports false for ordinary comments and for build constraints, which are reported
+// in GFPGoFile.BuildConstraint instead.
+func parseDirective(fset *token.FileSet, comment *ast.Comment) (GFPDirective, bool) {
+	text := strings.TrimRight(comment.Text, " \t")
+	directive := GFPDirective{Text: text, Line: fset.Position(comment.Pos()).Line}
+
+	switch {
+	case text == "//nolint" || strings.HasPrefix(text, "//nolint:") || strings.HasPrefix(text, "//nolint "):
+		// "//nolint:errcheck,gosec // explanation"
+		directive.Name = "nolint"
+		linters, _, _ := strings.Cut(strings.TrimPrefix(text, "//nolint"), "//")
+		if linters, ok := strings.CutPrefix(strings.TrimSpace(linters), ":"); ok {
+			for _, linter := range strings.Split(linters, ",") {
+				if linter = strings.TrimSpace(linter); linter != "" {
+					directive.Args = append(directive.Args, linter)
+				}
+			}
+		}
+	case strings.HasPrefix(text, "//export ") || strings.HasPrefix(text, "//extern "):
+		// cgo and gccgo directives naming a symbol.
+		name, args, _ := strings.Cut(strings.TrimPrefix(text, "//"), " ")
+		directive.Name = name
+		directive.Args = strings.Fields(args)
+	case toolDirective.MatchString(text):
+		m := toolDirective.FindStringSubmatch(text)
+		if m[1] == "go:build" {
+			return GFPDirective{}, false
+		}
+		directive.Name = m[1]
+		directive.Args = splitDirectiveArgs(m[2])
+	case marker.MatchString(text):
+		m := marker.FindStringSubmatch(text)
+		if m[1] == "+build" {
+			return GFPDirective{}, false
+		}
+		directive.Name, directive.Args = parseMarker(m[1], m[2])
+	default:
+		return GFPDirective{}, false
+	}
+	return directive, true
+}
+
+// splitDirectiveArgs splits the arguments of a directive at spaces. Like go generate and
+// go:embed, double-quoted and back-quoted arguments may contain spaces and are unquoted.
+func splitDirectiveArgs(s string) []string {
+	var args []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		end := strings.IndexAny(s, " \t")
+		if s[0] == '"' || s[0] == '`' {
+			end = quotedEnd(s) + 1
+		}
+		if end <= 0 || end > len(s) {
+			end = len(s)
+		}
+		args = append(args, unquote(s[:end]))
+		s = s[end:]
+	}
+	return args
+}
+
+// parseMarker splits a marker into its name and arguments. A single value follows the
+// name after "=" ("+kubebuilder:validation:Minimum=1"), while named arguments are separated
+// by commas and the first one follows the last colon of the name
+// ("+kubebuilder:printcolumn:name=Age,type=date"). Quoted values are kept as written.
+func parseMarker(name, value string) (string, []string) {
+	if value == "" {
+		return name, nil
+	}
+	args := splitMarkerArgs(value)
+	if len(args) > 1 && namedArg.MatchString(args[1]) {
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			args[0] = name[i+1:] + "=" + args[0]
+			name = name[:i]
+		}
+	}
+	return name, args
+}
+
+// splitMarkerArgs splits marker arguments at commas outside of quotes and braces.
+func splitMarkerArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '`':
+			i = quotedEnd(s[i:]) + i
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, s[start:])
+}
+
+// quotedEnd returns the index of the quote closing the quoted string at the start of s,
+// or len(s) if it is not closed.
+func quotedEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return len(s)
+}
+
+// directiveCollector collects the directives of a file, keeping track of the comments
+// that were attached to a declaration.
+type directiveCollector struct {
+	fset     *token.FileSet
+	file     *ast.File
+	attached map[*ast.Comment]bool
+}
+
+// collect returns the directives in the given comment groups and marks them as attached.
+// Nil groups and groups passed more than once are skipped.
+func (c *directiveCollector) collect(groups ...*ast.CommentGroup) []GFPDirective {
+	var directives []GFPDirective
+	seen := make(map[*ast.CommentGroup]bool, len(groups))
+	for _, group := range groups {
+		if group == nil || seen[group] {
+			continue
+		}
+		seen[group] = true
+		for _, comment := range group.List {
+			if directive, ok := parseDirective(c.fset, comment); ok {
+				c.attached[comment] = true
+				directives = append(directives, directive)
+			}
+		}
+	}
+	return directives
+}
+
+// leading returns the comment group separated by at most one blank line from the start
+// of a top-level declaration (including its doc comment), if it consists of markers only.
+// Tools such as kubebuilder expect their markers in such a group:
+//
+//	// +kubebuilder:object:root=true
+//
+//	// CronJob is the Schema for the cronjobs API.
+//	type CronJob struct {
+func (c *directiveCollector) leading(decl ast.Decl, doc *ast.CommentGroup) *ast.CommentGroup {
+	start := decl.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+
+	comments, decls := c.file.Comments, c.file.Decls
+	i := sort.Search(len(comments), func(i int) bool { return comments[i].End() >= start })
+	if i == 0 {
+		return nil
+	}
+	previous := comments[i-1]
+	if previous.Pos() < c.file.Name.End() ||
+		c.fset.Position(start).Line-c.fset.Position(previous.End()).Line > 2 {
+		return nil
+	}
+	// The group must not share a line with the previous declaration, e.g. as its trailing
+	// comment.
+	j := sort.Search(len(decls), func(j int) bool { return decls[j].Pos() >= start })
+	if j > 0 && c.fset.Position(decls[j-1].End()).Line >= c.fset.Position(previous.Pos()).Line {
+		return nil
+	}
+	for _, comment := range previous.List {
+		if directive, ok := parseDirective(c.fset, comment); !ok || !strings.HasPrefix(directive.Name, "+") {
+			return nil
+		}
+	}
+	return previous
+}
+
+// annotateDirectives attaches the directives of a file to the declarations they annotate
+// and stores the remaining ones in GFPGoFile.Directives. A directive annotates a
+// declaration when it appears in the declaration's doc comment, the doc comment of its
+// parenthesized group, a markers-only comment group just above it, or its trailing line
+// comment. Directives in function bodies are attached to the function.
+func annotateDirectives(fset *token.FileSet, file *ast.File, goFile *GFPGoFile) {
+	c := &directiveCollector{fset: fset, file: file, attached: make(map[*ast.Comment]bool)}
+
+	var constants, variables, types, interfaces, functions, methods int
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			leading := c.leading(d, d.Doc)
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					directives := c.collect(leading, d.Doc, s.Doc, s.Comment)
+					for range s.Names {
+						switch d.Tok {
+						case token.CONST:
+							goFile.Constants[constants].Directives = directives
+							constants++
+						case token.VAR:
+							goFile.Variables[variables].Directives = directives
+							variables++
+						}
+					}
+				case *ast.TypeSpec:
+					directives := c.collect(leading, d.Doc, s.Doc, s.Comment)
+					if it, ok := s.Type.(*ast.InterfaceType); ok {
+						iface := &goFile.Interfaces[interfaces]
+						iface.Directives = directives
+						if it.Methods != nil {
+							annotateInterfaceDirectives(c, it, iface)
+						}
+						interfaces++
+						continue
+					}
+					t := &goFile.Types[types]
+					t.Directives = directives
+					if st, ok := s.Type.(*ast.StructType); ok && st.Fields != nil {
+						annotateFieldDirectives(c, st, t)
+					}
+					types++
+				}
+			}
+		case *ast.FuncDecl:
+			groups := append([]*ast.CommentGroup{c.leading(d, d.Doc), d.Doc}, commentsWithin(c.file, d)...)
+			directives := c.collect(groups...)
+			if d.Recv == nil {
+				goFile.Functions[functions].Directives = directives
+				functions++
+			} else {
+				goFile.Methods[methods].Directives = directives
+				methods++
+			}
+		}
+	}
+
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if directive, ok := parseDirective(fset, comment); ok && !c.attached[comment] {
+				goFile.Directives = append(goFile.Directives, directive)
+			}
+		}
+	}
+}
+
+// annotateFieldDirectives attaches directives to the fields of a struct type, which are
+// listed in the order of parseFields.
+func annotateFieldDirectives(c *directiveCollector, st *ast.StructType, t *GFPType) {
+	i := 0
+	for _, field := range st.Fields.List {
+		directives := c.collect(field.Doc, field.Comment)
+		for n := max(len(field.Names), 1); n > 0; n-- {
+			t.Fields[i].Directives = directives
+			i++
+		}
+	}
+}
+
+// annotateInterfaceDirectives attaches directives to the methods of an interface, which
+// are listed in the order of parseInterface.
+func annotateInterfaceDirectives(c *directiveCollector, it *ast.InterfaceType, iface *GFPInterface) {
+	i := 0
+	for _, elem := range it.Methods.List {
+		if _, ok := elem.Type.(*ast.FuncType); ok && len(elem.Names) > 0 {
+			iface.Methods[i].Directives = c.collect(elem.Doc, elem.Comment)
+			i++
+		}
+	}
+}
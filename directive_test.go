@@ -0,0 +1,131 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		text string
+		name string
+		args []string
+		ok   bool
+	}{
+		{"//go:generate stringer -type=Kind", "go:generate", []string{"stringer", "-type=Kind"}, true},
+		{`//go:generate sh -c "echo hello world"`, "go:generate", []string{"sh", "-c", "echo hello world"}, true},
+		{"//go:embed static/*.html `my file.txt`", "go:embed", []string{"static/*.html", "my file.txt"}, true},
+		{"//go:linkname localName runtime.nanotime", "go:linkname", []string{"localName", "runtime.nanotime"}, true},
+		{"//go:noinline", "go:noinline", nil, true},
+		{"//lint:ignore SA1019 still needed", "lint:ignore", []string{"SA1019", "still", "needed"}, true},
+		{"//lint:file-ignore U1000 kept for reflection", "lint:file-ignore", []string{"U1000", "kept", "for", "reflection"}, true},
+		{"//nolint", "nolint", nil, true},
+		{"//nolint:errcheck, gosec // checked by the caller", "nolint", []string{"errcheck", "gosec"}, true},
+		{"//export Callback", "export", []string{"Callback"}, true},
+		{"//+kubebuilder:object:root=true", "+kubebuilder:object:root", []string{"true"}, true},
+		{"// +kubebuilder:validation:Enum=a;b;c", "+kubebuilder:validation:Enum", []string{"a;b;c"}, true},
+		{`// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"`, "+kubebuilder:printcolumn", []string{`name="Age"`, `type="date"`, `JSONPath=".metadata.creationTimestamp"`}, true},
+		{"// +optional", "+optional", nil, true},
+		{"//go:build linux", "", nil, false},
+		{"// +build linux", "", nil, false},
+		{"// go:generate is not a directive with a space", "", nil, false},
+		{"// +1 for this", "", nil, false},
+		{"/* go:noinline */", "", nil, false},
+	}
+
+	fset := token.NewFileSet()
+	for _, tt := range tests {
+		directive, ok := parseDirective(fset, &ast.Comment{Text: tt.text})
+		if ok != tt.ok || directive.Name != tt.name || !reflect.DeepEqual(directive.Args, tt.args) {
+			t.Errorf("parseDirective(%q) = %q %q %v, want %q %q %v", tt.text, directive.Name, directive.Args, ok, tt.name, tt.args, tt.ok)
+		}
+	}
+}
+
+const directiveSource = `//go:build linux
+
+// Package gen is generated.
+package gen
+
+import "embed"
+
+//go:generate stringer -type=Kind
+
+// Kind is a kind.
+//
+//go:generate enumer -type=Kind
+type Kind int
+
+// +kubebuilder:object:root=true
+
+// CronJob is a resource.
+type CronJob struct {
+	// +optional
+	Schedule string
+	Suspend  bool //nolint:revive
+}
+
+var (
+	//go:embed static
+	static embed.FS
+)
+
+// Now returns the time.
+//
+//go:noinline
+func Now() int64 {
+	x := now() //nolint:errcheck
+	return x
+}
+
+type Store interface {
+	//go:generate mockgen
+	Get() error
+}
+
+//export Callback
+func (s *store) Callback() {}
+`
+
+func TestAnnotateDirectives(t *testing.T) {
+	goFile := mustParseSource(t, "gen.go", directiveSource)
+
+	names := func(directives []GFPDirective) []string {
+		var result []string
+		for _, d := range directives {
+			result = append(result, d.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		directives []GFPDirective
+		expected   []string
+	}{
+		{"file", goFile.Directives, []string{"go:generate"}},
+		{"Kind", goFile.Types[0].Directives, []string{"go:generate"}},
+		{"CronJob", goFile.Types[1].Directives, []string{"+kubebuilder:object:root"}},
+		{"Schedule", goFile.Types[1].Fields[0].Directives, []string{"+optional"}},
+		{"Suspend", goFile.Types[1].Fields[1].Directives, []string{"nolint"}},
+		{"static", goFile.Variables[0].Directives, []string{"go:embed"}},
+		{"Now", goFile.Functions[0].Directives, []string{"go:noinline", "nolint"}},
+		{"Store.Get", goFile.Interfaces[0].Methods[0].Directives, []string{"go:generate"}},
+		{"Callback", goFile.Methods[0].Directives, []string{"export"}},
+	}
+
+	for _, tt := range tests {
+		if got := names(tt.directives); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected directives %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+
+	if d := goFile.Directives; len(d) == 1 && (d[0].Line != 8 || d[0].Args[0] != "stringer") {
+		t.Errorf("Unexpected file-level directive %+v", d[0])
+	}
+	if d := goFile.Types[0].Directives; len(d) == 1 && d[0].Args[0] != "enumer" {
+		t.Errorf("Expected the enumer directive on Kind, got %+v", d[0])
+	}
+}
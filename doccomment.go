@@ -0,0 +1,151 @@
+package gofileparser
+
+import (
+	"go/doc"
+	"go/doc/comment"
+	"strings"
+)
+
+// parseDocComment parses the text of a doc comment, as returned by ast.CommentGroup.Text,
+// into a GFP_DocComment structure. It returns nil for an empty comment.
+//
+// Doc links to packages ([fmt] or [pkg.Symbol]) are resolved through imports, which maps
+// import names to import paths, and fall back to the standard library. Doc links to
+// symbols of the current package ([Symbol] or [Type.Method]) are assumed to exist, as
+// the symbols of other files are not known while a file is parsed.
+func parseDocComment(text string, imports map[string]string) *GFPDocComment {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	parser := comment.Parser{
+		LookupPackage: func(name string) (string, bool) {
+			importPath, ok := imports[name]
+			return importPath, ok
+		},
+		LookupSym: func(recv, name string) bool {
+			return true
+		},
+	}
+	d := &docCommentBuilder{doc: &GFPDocComment{
+		Synopsis: new(doc.Package).Synopsis(text),
+	}}
+	d.doc.Blocks = d.blocks(parser.Parse(text).Content)
+	return d.doc
+}
+
+// docCommentBuilder converts the blocks of a parsed comment, collecting its doc links and
+// deprecation notice along the way.
+type docCommentBuilder struct {
+	doc *GFPDocComment
+}
+
+// blocks converts a list of go/doc/comment blocks.
+func (d *docCommentBuilder) blocks(blocks []comment.Block) []GFPDocBlock {
+	var result []GFPDocBlock
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *comment.Heading:
+			spans, text := d.spans(b.Text)
+			result = append(result, GFPDocBlock{Kind: "heading", Text: text, Spans: spans})
+		case *comment.Paragraph:
+			spans, text := d.spans(b.Text)
+			if notice, ok := strings.CutPrefix(text, "Deprecated: "); ok && d.doc.Deprecated == "" {
+				d.doc.Deprecated = strings.TrimSpace(notice)
+			}
+			result = append(result, GFPDocBlock{Kind: "paragraph", Text: text, Spans: spans})
+		case *comment.Code:
+			result = append(result, GFPDocBlock{Kind: "code", Text: b.Text})
+		case *comment.List:
+			list := GFPDocBlock{Kind: "list"}
+			for _, item := range b.Items {
+				list.Items = append(list.Items, GFPDocListItem{
+					Number: item.Number,
+					Blocks: d.blocks(item.Content),
+				})
+			}
+			result = append(result, list)
+		}
+	}
+	return result
+}
+
+// spans converts inline text and returns it together with its plain text.
+func (d *docCommentBuilder) spans(texts []comment.Text) ([]GFPDocSpan, string) {
+	var spans []GFPDocSpan
+	var plain strings.Builder
+	for _, text := range texts {
+		var span GFPDocSpan
+		switch t := text.(type) {
+		case comment.Plain:
+			span = GFPDocSpan{Kind: "text", Text: string(t)}
+		case comment.Italic:
+			span = GFPDocSpan{Kind: "italic", Text: string(t)}
+		case *comment.Link:
+			span = GFPDocSpan{Kind: "link", Text: plainText(t.Text), URL: t.URL}
+		case *comment.DocLink:
+			link := GFPDocLink{
+				Text:       plainText(t.Text),
+				ImportPath: t.ImportPath,
+				Recv:       t.Recv,
+				Name:       t.Name,
+			}
+			d.doc.Links = append(d.doc.Links, link)
+			span = GFPDocSpan{Kind: "docLink", Text: link.Text, DocLink: &link}
+		default:
+			continue
+		}
+		spans = append(spans, span)
+		plain.WriteString(span.Text)
+	}
+	return spans, plain.String()
+}
+
+// plainText returns the text of inline elements without any markup.
+func plainText(texts []comment.Text) string {
+	var plain strings.Builder
+	for _, text := range texts {
+		switch t := text.(type) {
+		case comment.Plain:
+			plain.WriteString(string(t))
+		case comment.Italic:
+			plain.WriteString(string(t))
+		case *comment.Link:
+			plain.WriteString(plainText(t.Text))
+		case *comment.DocLink:
+			plain.WriteString(plainText(t.Text))
+		}
+	}
+	return plain.String()
+}
+
+// annotateDocComments sets the parsed doc comment of every documented declaration in goFile.
+func annotateDocComments(goFile *GFPGoFile, imports map[string]string) {
+	goFile.FileDocComment = parseDocComment(goFile.FileDoc, imports)
+	for i := range goFile.Constants {
+		goFile.Constants[i].DocComment = parseDocComment(goFile.Constants[i].Doc, imports)
+	}
+	for i := range goFile.Variables {
+		goFile.Variables[i].DocComment = parseDocComment(goFile.Variables[i].Doc, imports)
+	}
+	for i := range goFile.Types {
+		t := &goFile.Types[i]
+		t.DocComment = parseDocComment(t.Doc, imports)
+		for j := range t.Fields {
+			t.Fields[j].DocComment = parseDocComment(t.Fields[j].Doc, imports)
+		}
+	}
+	for i := range goFile.Functions {
+		goFile.Functions[i].DocComment = parseDocComment(goFile.Functions[i].Doc, imports)
+	}
+	for i := range goFile.Methods {
+		goFile.Methods[i].DocComment = parseDocComment(goFile.Methods[i].Doc, imports)
+	}
+	for i := range goFile.Interfaces {
+		iface := &goFile.Interfaces[i]
+		iface.DocComment = parseDocComment(iface.Doc, imports)
+		for j := range iface.Methods {
+			iface.Methods[j].DocComment = parseDocComment(iface.Methods[j].Doc, imports)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package gofileparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const docCommentSource = `// Package store keeps items.
+//
+// # Usage
+//
+// Create a [Store] with [New] and read from an [io.Reader] or a [yaml.Node].
+// See the [Go website] for more.
+//
+//   - first item
+//   - second item
+//
+// Steps:
+//
+//  1. open
+//  2. close
+//
+// Example:
+//
+//	s := store.New()
+//
+// [Go website]: https://go.dev
+package store
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Get returns an item. It never fails.
+//
+// Deprecated: Use [Store.Lookup] instead.
+func Get() {}
+
+func Undocumented() {}
+`
+
+func TestParseDocComment(t *testing.T) {
+	goFile := mustParseSource(t, "store.go", docCommentSource)
+
+	doc := goFile.FileDocComment
+	if doc == nil {
+		t.Fatalf("Expected a parsed file doc")
+	}
+	if doc.Synopsis != "Package store keeps items." {
+		t.Errorf("Unexpected synopsis %q", doc.Synopsis)
+	}
+
+	var kinds []string
+	for _, block := range doc.Blocks {
+		kinds = append(kinds, block.Kind)
+	}
+	expectedKinds := []string{"paragraph", "heading", "paragraph", "list", "paragraph", "list", "paragraph", "code"}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Fatalf("Expected blocks %v, got %v", expectedKinds, kinds)
+	}
+	if doc.Blocks[1].Text != "Usage" {
+		t.Errorf("Expected heading Usage, got %q", doc.Blocks[1].Text)
+	}
+	if items := doc.Blocks[3].Items; len(items) != 2 || items[0].Number != "" || items[1].Blocks[0].Text != "second item" {
+		t.Errorf("Unexpected bullet list %+v", items)
+	}
+	if items := doc.Blocks[5].Items; len(items) != 2 || items[1].Number != "2" || items[1].Blocks[0].Text != "close" {
+		t.Errorf("Unexpected numbered list %+v", items)
+	}
+	if doc.Blocks[7].Text != "s := store.New()\n" {
+		t.Errorf("Unexpected code block %q", doc.Blocks[7].Text)
+	}
+
+	expectedLinks := []GFPDocLink{
+		{Text: "Store", Name: "Store"},
+		{Text: "New", Name: "New"},
+		{Text: "io.Reader", ImportPath: "io", Name: "Reader"},
+		{Text: "yaml.Node", ImportPath: "gopkg.in/yaml.v3", Name: "Node"},
+	}
+	if !reflect.DeepEqual(doc.Links, expectedLinks) {
+		t.Errorf("Unexpected doc links:\ngot  %+v\nwant %+v", doc.Links, expectedLinks)
+	}
+
+	var link *GFPDocSpan
+	for i, span := range doc.Blocks[2].Spans {
+		if span.Kind == "link" {
+			link = &doc.Blocks[2].Spans[i]
+		}
+	}
+	if link == nil || link.Text != "Go website" || link.URL != "https://go.dev" {
+		t.Errorf("Expected a link to https://go.dev, got %+v", link)
+	}
+
+	fn := goFile.Functions[0].DocComment
+	if fn == nil || fn.Synopsis != "Get returns an item." || fn.Deprecated != "Use Store.Lookup instead." {
+		t.Errorf("Unexpected function doc %+v", fn)
+	}
+	if fn != nil && (len(fn.Links) != 1 || fn.Links[0].Recv != "Store" || fn.Links[0].Name != "Lookup") {
+		t.Errorf("Expected a method doc link, got %+v", fn.Links)
+	}
+	if goFile.Functions[1].DocComment != nil {
+		t.Errorf("Expected no doc comment for an undocumented function")
+	}
+}
+
+func TestParseDocCommentPublic(t *testing.T) {
+	if ParseDocComment("") != nil {
+		t.Errorf("Expected nil for an empty comment")
+	}
+
+	doc := ParseDocComment("Wraps an [io.Writer] and a [yaml.Node].\n")
+	if doc == nil || len(doc.Links) != 1 || doc.Links[0].ImportPath != "io" {
+		t.Errorf("Expected only the standard library link to resolve, got %+v", doc)
+	}
+}
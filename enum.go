@@ -0,0 +1,109 @@
+package gofileparser
+
+import (
+	"go/types"
+	"strings"
+)
+
+// findEnums detects the enums of the given packages: defined types based on a predeclared
+// basic type, together with the constants of that type.
+func findEnums(pkgs []*GFPPackage) []GFPEnum {
+	var enums []GFPEnum
+	for _, pkg := range pkgs {
+		defs := make(map[string]string, len(pkg.Types))
+		for _, t := range pkg.Types {
+			defs[t.Name] = t.Def
+		}
+
+		for _, t := range pkg.Types {
+			if t.Alias {
+				continue
+			}
+			underlying, ok := basicUnderlying(t.Name, defs)
+			if !ok {
+				continue
+			}
+			enum := GFPEnum{
+				Name:       t.Name,
+				Package:    pkg.ImportPath,
+				Underlying: underlying,
+				HasString:  hasStringMethod(t),
+				Line:       t.Line,
+			}
+			enum.Members = enumMembers(pkg.Constants, t.Name)
+			if len(enum.Members) > 0 {
+				enums = append(enums, enum)
+			}
+		}
+	}
+	return enums
+}
+
+// basicUnderlying returns the predeclared basic type a defined type is based on, following
+// other types of the same package (e.g. "type Level uint8" and "type Severity Level").
+func basicUnderlying(name string, defs map[string]string) (string, bool) {
+	def, ok := defs[name]
+	for depth := 0; ok && depth < 10; depth++ {
+		if obj, isType := types.Universe.Lookup(def).(*types.TypeName); isType {
+			if _, isBasic := obj.Type().(*types.Basic); isBasic {
+				return def, true
+			}
+			return "", false
+		}
+		def, ok = defs[def]
+	}
+	return "", false
+}
+
+// enumMembers returns the constants of the named type, skipping blank ones. A constant
+// declared by implicit repetition belongs to the type if the constant at the same position
+// in the last spec with values does, as in "Red = Color(iota); Green; Blue".
+func enumMembers(constants []GFPConstant, typeName string) []GFPConstant {
+	var members []GFPConstant
+	var inherited []bool // Membership of the constants of the last spec with values
+	position := 0
+	for i, c := range constants {
+		// The names of one spec share its range.
+		if i > 0 && c.Range == constants[i-1].Range {
+			position++
+		} else {
+			position = 0
+		}
+
+		var member bool
+		if c.Value != "" {
+			if position == 0 {
+				inherited = inherited[:0]
+			}
+			member = isEnumMember(c, typeName)
+			inherited = append(inherited, member)
+		} else {
+			member = position < len(inherited) && inherited[position]
+		}
+		if member && c.Name != "_" {
+			members = append(members, c)
+		}
+	}
+	return members
+}
+
+// isEnumMember reports whether a constant is of the named type, either declared with it
+// ("Red Color = iota", including implicit repetition) or converted to it ("Red = Color(0)").
+func isEnumMember(c GFPConstant, typeName string) bool {
+	if c.Type != "" {
+		return c.Type == typeName
+	}
+	return strings.HasPrefix(c.Value, typeName+"(") && strings.HasSuffix(c.Value, ")")
+}
+
+// hasStringMethod reports whether a type declares the method String() string, with a value
+// or pointer receiver.
+func hasStringMethod(t GFPType) bool {
+	for _, method := range t.Methods {
+		if method.Name == "String" && len(method.Parameters) == 0 &&
+			len(method.Results) == 1 && method.Results[0].Type == "string" {
+			return true
+		}
+	}
+	return false
+}
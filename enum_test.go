@@ -0,0 +1,126 @@
+package gofileparser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestFindEnums(t *testing.T) {
+	dir := t.TempDir()
+	createTempGoFile(t, dir, "color.go", `package paint
+
+// Color is a color.
+type Color int
+
+const (
+	_ Color = iota
+	Red
+	Green
+	Blue
+)
+
+func (c Color) String() string { return "" }
+
+type Level uint8
+
+type Severity Level
+
+const (
+	Low  Severity = 1
+	High Severity = Low << 1
+)
+
+const Default = Severity(3)
+
+type Status string
+
+const (
+	Active   Status = "active"
+	Inactive Status = "inactive"
+)
+
+func (s *Status) String(prefix string) string { return prefix }
+
+type Unused int
+
+type Weekday uint
+
+const (
+	Monday = Weekday(iota + 1)
+	Tuesday
+	Wednesday
+)
+
+type Code = int
+
+const OK Code = 0
+
+type Point struct{ X int }
+
+const Origin = 0
+`)
+	createTempGoFile(t, dir, "more.go", "package paint\n\nconst Purple Color = 7\n")
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	type member struct{ name, value string }
+	type enum struct {
+		name, underlying string
+		hasString        bool
+		members          []member
+	}
+	expected := []enum{
+		{"Color", "int", true, []member{{"Red", "1"}, {"Green", "2"}, {"Blue", "3"}, {"Purple", "7"}}},
+		{"Severity", "uint8", false, []member{{"Low", "1"}, {"High", "2"}, {"Default", "3"}}},
+		{"Status", "string", false, []member{{"Active", `"active"`}, {"Inactive", `"inactive"`}}},
+		{"Weekday", "uint", false, []member{{"Monday", "1"}, {"Tuesday", "2"}, {"Wednesday", "3"}}},
+	}
+
+	var got []enum
+	for _, e := range FindEnums(pkg) {
+		g := enum{e.Name, e.Underlying, e.HasString, nil}
+		for _, m := range e.Members {
+			g.members = append(g.members, member{m.Name, m.ExactValue})
+		}
+		got = append(got, g)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected enums:\ngot  %+v\nwant %+v", got, expected)
+	}
+}
+
+func TestBasicUnderlying(t *testing.T) {
+	defs := map[string]string{
+		"Level":    "uint8",
+		"Severity": "Level",
+		"Loop":     "Loop",
+		"Point":    "struct{ X int }",
+		"Handler":  "func()",
+		"ID":       "Ext",
+	}
+
+	tests := []struct {
+		name       string
+		underlying string
+		ok         bool
+	}{
+		{"Level", "uint8", true},
+		{"Severity", "uint8", true},
+		{"Loop", "", false},
+		{"Point", "", false},
+		{"Handler", "", false},
+		{"ID", "", false},
+		{"Missing", "", false},
+	}
+
+	for _, tt := range tests {
+		underlying, ok := basicUnderlying(tt.name, defs)
+		if underlying != tt.underlying || ok != tt.ok {
+			t.Errorf("basicUnderlying(%q) = %q, %v, want %q, %v", tt.name, underlying, ok, tt.underlying, tt.ok)
+		}
+	}
+}
@@ -15,5 +15,8 @@ func main() {
 	assert.NoError(nil, err)
 	assert.NotNil(nil, goFile)
 
-	fmt.Println(goFile)
+	data, err := gofileparser.ToJSON(goFile, gofileparser.GFPJSONOptions{Indent: "  ", OmitContent: true})
+	assert.NoError(nil, err)
+
+	fmt.Println(string(data))
 }
\ No newline at end of file
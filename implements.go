@@ -0,0 +1,362 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// findImplementations checks every concrete type of pkgs against the given interfaces and
+// returns one entry per pair that implements the interface or shares at least one method
+// name with it. Types are never reported to implement an interface with unresolved embeds.
+func findImplementations(ifaces []qualifiedInterface, pkgs []*GFPPackage) []GFPImplementation {
+	var result []GFPImplementation
+	scopes := newTypeScopes()
+	for _, iface := range ifaces {
+		methods, unresolved := interfaceMethodSet(iface, scopes, pkgs)
+		if len(methods) == 0 {
+			continue
+		}
+		for _, pkg := range pkgs {
+			for _, typ := range pkg.Types {
+				impl, related := checkImplementation(iface, methods, scopes, pkg, typ)
+				if !related {
+					continue
+				}
+				if len(unresolved) > 0 {
+					impl.UnresolvedEmbeds = unresolved
+					impl.ByValue, impl.ByPointer = false, false
+				}
+				result = append(result, impl)
+			}
+		}
+	}
+	return result
+}
+
+// qualifiedInterface is an interface together with the package that declares it.
+type qualifiedInterface struct {
+	iface GFPInterface
+	pkg   *GFPPackage // may be nil for interfaces given without a package
+}
+
+// qualifiedMethod is an interface method together with the scope its types are written in.
+type qualifiedMethod struct {
+	method GFPInterfaceMethod
+	scope  typeScope
+}
+
+// packageInterfaces returns all interfaces declared in pkgs.
+func packageInterfaces(pkgs []*GFPPackage) []qualifiedInterface {
+	var ifaces []qualifiedInterface
+	for _, pkg := range pkgs {
+		for _, iface := range pkg.Interfaces {
+			ifaces = append(ifaces, qualifiedInterface{iface: iface, pkg: pkg})
+		}
+	}
+	return ifaces
+}
+
+// typeScope resolves the names used in the type expressions of one file: package
+// qualifiers through the file's imports and unqualified names through the declarations
+// of its package.
+type typeScope struct {
+	pkg     *GFPPackage       // Package of the file, nil if unknown
+	imports map[string]string // Import paths by the names the file refers to them with
+	locals  map[string]bool   // Types and interfaces declared in pkg
+}
+
+// typeScopes builds and caches the type scopes of files and the declarations of packages,
+// along with the importers used to load embedded interfaces of other packages.
+type typeScopes struct {
+	files     map[*GFPGoFile]typeScope
+	locals    map[*GFPPackage]map[string]bool
+	importers map[string]*localImporter // Importers by the path of their module, "" for none
+}
+
+// newTypeScopes returns an empty typeScopes.
+func newTypeScopes() *typeScopes {
+	return &typeScopes{
+		files:     make(map[*GFPGoFile]typeScope),
+		locals:    make(map[*GFPPackage]map[string]bool),
+		importers: make(map[string]*localImporter),
+	}
+}
+
+// scope returns the type scope of a file of pkg. The file may be nil if it is unknown, in
+// which case package qualifiers cannot be resolved.
+func (s *typeScopes) scope(pkg *GFPPackage, file *GFPGoFile) typeScope {
+	if pkg == nil {
+		return typeScope{}
+	}
+	if scope, ok := s.files[file]; ok && file != nil {
+		return scope
+	}
+	locals, ok := s.locals[pkg]
+	if !ok {
+		locals = make(map[string]bool, len(pkg.Types)+len(pkg.Interfaces))
+		for _, t := range pkg.Types {
+			locals[t.Name] = true
+		}
+		for _, iface := range pkg.Interfaces {
+			locals[iface.Name] = true
+		}
+		s.locals[pkg] = locals
+	}
+	scope := typeScope{pkg: pkg, locals: locals}
+	if file != nil {
+		scope.imports = importPaths(file.Imports)
+		s.files[file] = scope
+	}
+	return scope
+}
+
+// interfaceScope returns the type scope of the file declaring an interface.
+func (s *typeScopes) interfaceScope(iface qualifiedInterface) typeScope {
+	var file *GFPGoFile
+	if iface.pkg != nil {
+		for _, f := range iface.pkg.Files {
+			for _, candidate := range f.Interfaces {
+				if candidate.Name == iface.iface.Name && candidate.Line == iface.iface.Line {
+					file = f
+				}
+			}
+		}
+	}
+	return s.scope(iface.pkg, file)
+}
+
+// methodScope returns the type scope of the file of pkg declaring a method.
+func (s *typeScopes) methodScope(pkg *GFPPackage, method GFPMethod) typeScope {
+	var file *GFPGoFile
+	for _, f := range pkg.Files {
+		for _, candidate := range f.Methods {
+			if candidate.Name == method.Name && candidate.Receiver == method.Receiver && candidate.Line == method.Line {
+				file = f
+			}
+		}
+	}
+	return s.scope(pkg, file)
+}
+
+// qualify rewrites a type expression so that it reads the same in every file: package
+// qualifiers are replaced by import paths and names declared in the package are
+// qualified with its import path. Expressions that cannot be parsed are returned as is.
+func (s typeScope) qualify(typ string) string {
+	if s.pkg == nil {
+		return typ
+	}
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+	pkgPath := s.pkg.ImportPath
+	if pkgPath == "" {
+		pkgPath = s.pkg.Name
+	}
+
+	skip := make(map[*ast.Ident]bool)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			for _, name := range x.Names {
+				skip[name] = true
+			}
+		case *ast.SelectorExpr:
+			if qualifier, ok := x.X.(*ast.Ident); ok {
+				if importPath, ok := s.imports[qualifier.Name]; ok {
+					qualifier.Name = importPath
+				}
+				return false
+			}
+		case *ast.Ident:
+			if !skip[x] && s.locals[x.Name] {
+				x.Name = pkgPath + "." + x.Name
+			}
+		}
+		return true
+	})
+	return exprToString(expr)
+}
+
+// importedMethods returns the methods of an interface of a package that is not among the
+// parsed packages, such as "io.Reader", type-checked from the standard library or the
+// module of the embedding package. Their types are qualified with import paths, as
+// typeScope.qualify writes them.
+func (s *typeScopes) importedMethods(embed string, scope typeScope) ([]GFPInterfaceMethod, bool) {
+	qualifier, name, qualified := strings.Cut(embed, ".")
+	importPath, ok := scope.imports[qualifier]
+	if !qualified || !ok || strings.Contains(name, "[") {
+		return nil, false
+	}
+	var module *goModule
+	if scope.pkg != nil && scope.pkg.Dir != "" {
+		module = findDiskModule(scope.pkg.Dir)
+	}
+	key := ""
+	if module != nil {
+		key = module.path
+	}
+	imp, ok := s.importers[key]
+	if !ok {
+		imp = newLocalImporter(token.NewFileSet(), module, build.Default)
+		s.importers[key] = imp
+	}
+	iface, err := importedInterface(imp, importPath, name)
+	if err != nil {
+		return nil, false
+	}
+	return interfaceMethods(iface, nil), true
+}
+
+// interfaceMethodSet returns the methods of an interface including those of embedded
+// interfaces found in pkgs or loaded from other packages, along with the embeds that could
+// not be resolved.
+func interfaceMethodSet(iface qualifiedInterface, scopes *typeScopes, pkgs []*GFPPackage) ([]qualifiedMethod, []string) {
+	var methods []qualifiedMethod
+	var unresolved []string
+	seen := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var collect func(iface qualifiedInterface)
+	collect = func(iface qualifiedInterface) {
+		key := iface.iface.Name
+		if iface.pkg != nil {
+			key = iface.pkg.ImportPath + "." + key
+		}
+		if visiting[key] {
+			return
+		}
+		visiting[key] = true
+
+		scope := scopes.interfaceScope(iface)
+		for _, method := range iface.iface.Methods {
+			if !seen[method.Name] {
+				seen[method.Name] = true
+				methods = append(methods, qualifiedMethod{method: method, scope: scope})
+			}
+		}
+		for _, embed := range iface.iface.Embeds {
+			if embedded, ok := resolveEmbed(embed, scope, pkgs); ok {
+				collect(embedded)
+				continue
+			}
+			imported, ok := scopes.importedMethods(embed, scope)
+			if !ok {
+				unresolved = append(unresolved, embed)
+				continue
+			}
+			for _, method := range imported {
+				if !seen[method.Name] {
+					seen[method.Name] = true
+					methods = append(methods, qualifiedMethod{method: method})
+				}
+			}
+		}
+	}
+	collect(iface)
+
+	return methods, unresolved
+}
+
+// resolveEmbed looks up an embedded interface such as "Closer" or "io.Closer". Unqualified
+// names are looked up in the package of the embedding file, qualified names in the package
+// of pkgs the file imports under the qualifier.
+func resolveEmbed(embed string, scope typeScope, pkgs []*GFPPackage) (qualifiedInterface, bool) {
+	if i := strings.Index(embed, "["); i >= 0 {
+		embed = embed[:i]
+	}
+	var pkg *GFPPackage
+	qualifier, name, qualified := strings.Cut(embed, ".")
+	if !qualified {
+		name = qualifier
+		pkg = scope.pkg
+	} else if importPath, ok := scope.imports[qualifier]; ok {
+		for _, candidate := range pkgs {
+			if candidate.ImportPath == importPath {
+				pkg = candidate
+				break
+			}
+		}
+	}
+	if pkg == nil {
+		return qualifiedInterface{}, false
+	}
+	for _, iface := range pkg.Interfaces {
+		if iface.Name == name {
+			return qualifiedInterface{iface: iface, pkg: pkg}, true
+		}
+	}
+	return qualifiedInterface{}, false
+}
+
+// checkImplementation compares the methods of a concrete type with the method set of an
+// interface. It reports false if the type has none of the interface's method names.
+func checkImplementation(iface qualifiedInterface, methods []qualifiedMethod, scopes *typeScopes, pkg *GFPPackage, typ GFPType) (GFPImplementation, bool) {
+	impl := GFPImplementation{
+		Interface:   iface.iface.Name,
+		Type:        typ.Name,
+		TypePackage: pkg.ImportPath,
+	}
+	if iface.pkg != nil {
+		impl.InterfacePackage = iface.pkg.ImportPath
+	}
+
+	related := false
+	for _, m := range methods {
+		want := m.method
+		var found *GFPMethod
+		for i := range typ.Methods {
+			if typ.Methods[i].Name == want.Name {
+				found = &typ.Methods[i]
+				break
+			}
+		}
+		if found == nil {
+			impl.Missing = append(impl.Missing, want.Name)
+			continue
+		}
+		related = true
+
+		scope := scopes.methodScope(pkg, *found)
+		if !sameTypes(want.Parameters, found.Parameters, m.scope, scope) || !sameTypes(want.Results, found.Results, m.scope, scope) {
+			impl.Mismatched = append(impl.Mismatched, GFPSignatureMismatch{
+				Method:   want.Name,
+				Expected: signatureString(want.Parameters, want.Results),
+				Actual:   signatureString(found.Parameters, found.Results),
+			})
+			continue
+		}
+		if strings.HasPrefix(found.Receiver, "*") {
+			impl.PointerMethods = append(impl.PointerMethods, want.Name)
+		}
+	}
+
+	impl.ByPointer = len(impl.Missing) == 0 && len(impl.Mismatched) == 0
+	impl.ByValue = impl.ByPointer && len(impl.PointerMethods) == 0
+	return impl, related
+}
+
+// sameTypes reports whether two parameter lists have the same types, ignoring names.
+// Resolved types are compared when both sides were type-checked, otherwise the source
+// text qualified through the scopes of the files declaring each list.
+func sameTypes(a, b []GFPParameter, aScope, bScope typeScope) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Variadic != b[i].Variadic {
+			return false
+		}
+		if a[i].TypeInfo != nil && b[i].TypeInfo != nil {
+			if a[i].TypeInfo.Type != b[i].TypeInfo.Type {
+				return false
+			}
+		} else if aScope.qualify(a[i].Type) != bScope.qualify(b[i].Type) {
+			return false
+		}
+	}
+	return true
+}
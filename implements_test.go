@@ -0,0 +1,199 @@
+package gofileparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImplementationMatrix(t *testing.T) {
+	src := []byte(`package store
+
+import (
+	"io"
+
+	"example.com/remote"
+)
+
+type Closer interface {
+	Close() error
+}
+
+type Store interface {
+	Closer
+	io.Reader
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+}
+
+type Mirror interface {
+	remote.Source
+	Get(key string) ([]byte, error)
+}
+
+type Empty interface{}
+
+type Memory struct{}
+
+func (m Memory) Get(k string) ([]byte, error) { return nil, nil }
+func (m *Memory) Put(k string, v []byte) error { return nil }
+func (m Memory) Close() error { return nil }
+
+type Disk struct{}
+
+func (d Disk) Read(p []byte) (int, error) { return 0, nil }
+func (d Disk) Get(k string) ([]byte, error) { return nil, nil }
+func (d *Disk) Put(k string, v []byte) error { return nil }
+func (d Disk) Close() error { return nil }
+
+type ReadOnly struct{}
+
+func (r ReadOnly) Get(key string) ([]byte, error) { return nil, nil }
+func (r ReadOnly) Close() error { return nil }
+
+type Legacy struct{}
+
+func (l Legacy) Get(key string) []byte { return nil }
+
+type Unrelated struct{}
+
+func (u Unrelated) Other() {}
+`)
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("store.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	pkg, err := newPackage(".", "example.com/store", []*GFPGoFile{goFile})
+	if err != nil {
+		t.Fatalf("newPackage failed: %v", err)
+	}
+
+	matrix := ImplementationMatrix(pkg)
+
+	results := make(map[string]GFPImplementation)
+	for _, impl := range matrix {
+		results[impl.Interface+"/"+impl.Type] = impl
+	}
+	if len(matrix) != 11 {
+		t.Errorf("Expected 11 entries, got %d: %+v", len(matrix), matrix)
+	}
+
+	memory := results["Store/Memory"]
+	if memory.ByPointer || memory.ByValue || !reflect.DeepEqual(memory.Missing, []string{"Read"}) || memory.InterfacePackage != "example.com/store" {
+		t.Errorf("Store/Memory not reported as missing the embedded io.Reader's Read: %+v", memory)
+	}
+
+	disk := results["Store/Disk"]
+	if !disk.ByPointer || disk.ByValue || !reflect.DeepEqual(disk.PointerMethods, []string{"Put"}) || len(disk.UnresolvedEmbeds) != 0 {
+		t.Errorf("Store/Disk not checked correctly: %+v", disk)
+	}
+
+	mirror := results["Mirror/ReadOnly"]
+	if mirror.ByPointer || mirror.ByValue || !reflect.DeepEqual(mirror.UnresolvedEmbeds, []string{"remote.Source"}) {
+		t.Errorf("Mirror/ReadOnly should not implement an interface with unresolved embeds: %+v", mirror)
+	}
+
+	readOnly := results["Store/ReadOnly"]
+	if readOnly.ByPointer || !reflect.DeepEqual(readOnly.Missing, []string{"Put", "Read"}) {
+		t.Errorf("Store/ReadOnly not reported as near-miss: %+v", readOnly)
+	}
+
+	legacy := results["Store/Legacy"]
+	expected := []GFPSignatureMismatch{{Method: "Get", Expected: "(string) ([]byte, error)", Actual: "(string) []byte"}}
+	if legacy.ByPointer || !reflect.DeepEqual(legacy.Mismatched, expected) {
+		t.Errorf("Store/Legacy mismatch not reported: %+v", legacy)
+	}
+
+	closer := results["Closer/ReadOnly"]
+	if !closer.ByValue || !closer.ByPointer {
+		t.Errorf("Closer/ReadOnly not checked correctly: %+v", closer)
+	}
+	if _, ok := results["Closer/Memory"]; !ok {
+		t.Errorf("Closer/Memory missing")
+	}
+
+	if _, ok := results["Store/Unrelated"]; ok {
+		t.Errorf("Unrelated types should not be reported")
+	}
+
+	found := FindImplementations(pkg.Interfaces[0], pkg, pkg)
+	if len(found) != 3 || found[0].Type != "Memory" || found[1].Type != "Disk" || found[2].Type != "ReadOnly" || found[0].InterfacePackage != "example.com/store" {
+		t.Errorf("FindImplementations not correct: %+v", found)
+	}
+}
+
+func TestFindImplementationsAcrossPackages(t *testing.T) {
+	sources := []struct{ name, importPath, src string }{
+		{"store", "example.com/m/store", `package store
+
+type Item struct{ ID string }
+
+type Store interface {
+	Get(id string) (Item, error)
+	Put(items ...Item) error
+}
+`},
+		{"s", "example.com/m/s", `package s
+
+type Item int
+
+type Store interface {
+	Get(id string) (Item, error)
+}
+`},
+		{"api", "example.com/m/api", `package api
+
+import base "example.com/m/store"
+
+type Service interface {
+	base.Store
+	Name() string
+}
+`},
+		{"impl", "example.com/m/impl", `package impl
+
+import st "example.com/m/store"
+
+type Item struct{}
+
+type Cache struct{}
+
+func (c *Cache) Get(string) (st.Item, error) { return st.Item{}, nil }
+func (c *Cache) Put(items ...st.Item) error  { return nil }
+func (c *Cache) Name() string                { return "cache" }
+
+type Local struct{}
+
+func (Local) Get(string) (Item, error) { return Item{}, nil }
+func (Local) Put(...st.Item) error     { return nil }
+`},
+	}
+	pkgs := make(map[string]*GFPPackage)
+	for _, source := range sources {
+		goFile, err := NewParser(GFPOptions{}).parseGoSource(source.name+".go", []byte(source.src))
+		if err != nil {
+			t.Fatalf("parseGoSource failed: %v", err)
+		}
+		pkgs[source.name], err = newPackage(source.name, source.importPath, []*GFPGoFile{goFile})
+		if err != nil {
+			t.Fatalf("newPackage failed: %v", err)
+		}
+	}
+	store, impl := pkgs["store"], pkgs["impl"]
+
+	found := FindImplementations(store.Interfaces[0], store, pkgs["s"], impl)
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 results, got %+v", found)
+	}
+	cache, local := found[0], found[1]
+	if cache.Type != "Cache" || cache.InterfacePackage != "example.com/m/store" || !cache.ByPointer || cache.ByValue {
+		t.Errorf("Expected *Cache to implement store.Store, got %+v", cache)
+	}
+	if local.Type != "Local" || local.ByPointer || len(local.Mismatched) != 1 || local.Mismatched[0].Method != "Get" {
+		t.Errorf("Expected Local.Get to return the wrong Item, got %+v", local)
+	}
+
+	found = FindImplementations(pkgs["api"].Interfaces[0], pkgs["api"], store, impl)
+	if len(found) != 2 || found[0].Type != "Cache" || !found[0].ByPointer || len(found[0].UnresolvedEmbeds) != 0 {
+		t.Errorf("Implementation through aliased qualified embed not found: %+v", found)
+	}
+}
@@ -0,0 +1,261 @@
+package gofileparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON document format written by ToJSON.
+// It is incremented whenever a field is renamed or removed or its meaning changes;
+// adding fields does not change it.
+const SchemaVersion = 1
+
+// jsonSchemaDialect is the JSON Schema draft the schema returned by JSONSchema follows.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// GFPDocument is the top-level JSON document written by ToJSON.
+// Exactly one of the result fields is set.
+type GFPDocument struct {
+	SchemaVersion   int                 `json:"schemaVersion"`             // Version of the document format, see SchemaVersion
+	File            *GFPGoFile          `json:"file,omitempty"`            // A single parsed file
+	Files           []*GFPGoFile        `json:"files,omitempty"`           // Several parsed files
+	Package         *GFPPackage         `json:"package,omitempty"`         // A parsed package
+	Packages        []*GFPPackage       `json:"packages,omitempty"`        // Several parsed packages
+	Module          *GFPModule          `json:"module,omitempty"`          // A parsed module
+	CallGraph       *GFPCallGraph       `json:"callGraph,omitempty"`       // A call graph built with BuildCallGraph
+	Implementations []GFPImplementation `json:"implementations,omitempty"` // Results of FindImplementations or ImplementationMatrix
+	Enums           []GFPEnum           `json:"enums,omitempty"`           // Results of FindEnums
+}
+
+// GFPJSONOptions configures the JSON output of ToJSON.
+type GFPJSONOptions struct {
+	// Indent indents the output with the given string per level. Empty writes compact JSON.
+	Indent string
+
+	// OmitContent leaves out the file content (GFPGoFile.Content).
+	OmitContent bool
+
+	// OmitBodies leaves out function and method bodies (GFPFunction.Body, GFPMethod.Body).
+	// Body ranges and call sites are kept.
+	OmitBodies bool
+}
+
+// newDocument wraps a parse result in a GFPDocument, applying the omissions of opts.
+// The result is never modified; parts that have to be stripped are copied.
+func newDocument(v any, opts GFPJSONOptions) (*GFPDocument, error) {
+	doc := &GFPDocument{SchemaVersion: SchemaVersion}
+	switch v := v.(type) {
+	case *GFPGoFile:
+		doc.File = opts.file(v)
+	case []*GFPGoFile:
+		doc.Files = make([]*GFPGoFile, len(v))
+		for i, file := range v {
+			doc.Files[i] = opts.file(file)
+		}
+	case *GFPPackage:
+		doc.Package = opts.pkg(v)
+	case []*GFPPackage:
+		doc.Packages = make([]*GFPPackage, len(v))
+		for i, pkg := range v {
+			doc.Packages[i] = opts.pkg(pkg)
+		}
+	case *GFPModule:
+		doc.Module = opts.module(v)
+	case *GFPCallGraph:
+		doc.CallGraph = v
+	case []GFPImplementation:
+		doc.Implementations = v
+	case []GFPEnum:
+		doc.Enums = v
+	default:
+		return nil, fmt.Errorf("cannot encode %T as JSON document", v)
+	}
+	return doc, nil
+}
+
+// file returns file without the parts omitted by o.
+func (o GFPJSONOptions) file(file *GFPGoFile) *GFPGoFile {
+	if file == nil || (!o.OmitContent && !o.OmitBodies) {
+		return file
+	}
+	stripped := *file
+	if o.OmitContent {
+		stripped.Content = ""
+	}
+	if o.OmitBodies {
+		stripped.Types = withoutBodies(file.Types)
+		stripped.Functions = functionsWithoutBodies(file.Functions)
+		stripped.Methods = methodsWithoutBodies(file.Methods)
+	}
+	return &stripped
+}
+
+// pkg returns pkg without the parts omitted by o.
+func (o GFPJSONOptions) pkg(pkg *GFPPackage) *GFPPackage {
+	if pkg == nil || (!o.OmitContent && !o.OmitBodies) {
+		return pkg
+	}
+	stripped := *pkg
+	stripped.Files = make([]*GFPGoFile, len(pkg.Files))
+	for i, file := range pkg.Files {
+		stripped.Files[i] = o.file(file)
+	}
+	if o.OmitBodies {
+		stripped.Types = withoutBodies(pkg.Types)
+		stripped.Functions = functionsWithoutBodies(pkg.Functions)
+		stripped.Methods = methodsWithoutBodies(pkg.Methods)
+	}
+	return &stripped
+}
+
+// module returns mod without the parts omitted by o.
+func (o GFPJSONOptions) module(mod *GFPModule) *GFPModule {
+	if mod == nil || (!o.OmitContent && !o.OmitBodies) {
+		return mod
+	}
+	stripped := *mod
+	stripped.Packages = make(map[string]*GFPPackage, len(mod.Packages))
+	for importPath, pkg := range mod.Packages {
+		stripped.Packages[importPath] = o.pkg(pkg)
+	}
+	return &stripped
+}
+
+// withoutBodies returns a copy of types whose attached methods have no bodies.
+func withoutBodies(types []GFPType) []GFPType {
+	if types == nil {
+		return nil
+	}
+	stripped := make([]GFPType, len(types))
+	for i, typ := range types {
+		typ.Methods = methodsWithoutBodies(typ.Methods)
+		stripped[i] = typ
+	}
+	return stripped
+}
+
+// functionsWithoutBodies returns a copy of fns without bodies.
+func functionsWithoutBodies(fns []GFPFunction) []GFPFunction {
+	if fns == nil {
+		return nil
+	}
+	stripped := make([]GFPFunction, len(fns))
+	for i, fn := range fns {
+		fn.Body = ""
+		stripped[i] = fn
+	}
+	return stripped
+}
+
+// methodsWithoutBodies returns a copy of methods without bodies.
+func methodsWithoutBodies(methods []GFPMethod) []GFPMethod {
+	if methods == nil {
+		return nil
+	}
+	stripped := make([]GFPMethod, len(methods))
+	for i, method := range methods {
+		method.Body = ""
+		stripped[i] = method
+	}
+	return stripped
+}
+
+// toJSON encodes a parse result as a GFPDocument.
+func toJSON(v any, opts GFPJSONOptions) ([]byte, error) {
+	doc, err := newDocument(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Indent != "" {
+		return json.MarshalIndent(doc, "", opts.Indent)
+	}
+	return json.Marshal(doc)
+}
+
+// jsonSchema builds the JSON Schema of GFPDocument from the Go types by reflection,
+// so the schema cannot drift from the struct tags.
+func jsonSchema() ([]byte, error) {
+	defs := map[string]any{}
+	root := schemaFor(reflect.TypeOf(GFPDocument{}), defs)
+	document := defs["GFPDocument"].(map[string]any)
+	document["properties"].(map[string]any)["schemaVersion"] = map[string]any{"const": SchemaVersion}
+
+	schema := map[string]any{
+		"$schema": jsonSchemaDialect,
+		"title":   "gofileparser document",
+		"$defs":   defs,
+	}
+	for key, value := range root {
+		schema[key] = value
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaFor returns the schema of t, adding the definitions of named structs to defs.
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), defs)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		def := map[string]any{"type": "object", "additionalProperties": false}
+		defs[t.Name()] = def // registered before the fields to terminate recursive types
+		properties := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, omitEmpty, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaFor(field.Type, defs)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+		def["properties"] = properties
+		def["required"] = required
+		return ref
+	}
+	return map[string]any{}
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is omitted when
+// empty. It reports false for fields that are not encoded.
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, true
+}
@@ -0,0 +1,145 @@
+package gofileparser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const jsonTestSource = `package shapes
+
+// Area reports the area.
+func Area(w, h int) int {
+	return w * h
+}
+
+type Square struct {
+	Side int ` + "`json:\"side\"`" + `
+}
+
+func (s *Square) Area() int {
+	return Area(s.Side, s.Side)
+}
+`
+
+func TestToJSON(t *testing.T) {
+	goFile := mustParseSource(t, "shapes.go", jsonTestSource)
+
+	data, err := toJSON(goFile, GFPJSONOptions{})
+	if err != nil {
+		t.Fatalf("toJSON failed: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if raw["schemaVersion"] != float64(SchemaVersion) {
+		t.Errorf("Expected schemaVersion %d, got %v", SchemaVersion, raw["schemaVersion"])
+	}
+	file := raw["file"].(map[string]any)
+	for _, key := range []string{"fileName", "package", "functions", "methods", "types", "content"} {
+		if _, ok := file[key]; !ok {
+			t.Errorf("Expected key %q in file, got %v", key, file)
+		}
+	}
+	if _, ok := file["Package"]; ok {
+		t.Errorf("Expected lowerCamelCase keys, got %v", file)
+	}
+	if _, ok := file["diagnostics"]; ok {
+		t.Errorf("Expected empty diagnostics to be omitted")
+	}
+
+	var doc GFPDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("cannot decode document: %v", err)
+	}
+	if !reflect.DeepEqual(doc.File, goFile) {
+		t.Errorf("Round trip changed the file:\ngot  %+v\nwant %+v", doc.File, goFile)
+	}
+}
+
+func TestToJSONOmit(t *testing.T) {
+	pkg, err := newPackage("shapes", "example.com/shapes", []*GFPGoFile{mustParseSource(t, "shapes.go", jsonTestSource)})
+	if err != nil {
+		t.Fatalf("newPackage failed: %v", err)
+	}
+
+	data, err := toJSON(pkg, GFPJSONOptions{OmitContent: true, OmitBodies: true, Indent: "  "})
+	if err != nil {
+		t.Fatalf("toJSON failed: %v", err)
+	}
+	if strings.Contains(string(data), `"content"`) || strings.Contains(string(data), `"body"`) {
+		t.Errorf("Expected content and bodies to be omitted:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"bodyRange"`) {
+		t.Errorf("Expected body ranges to be kept:\n%s", data)
+	}
+	if !strings.Contains(string(data), "\n  \"package\"") {
+		t.Errorf("Expected indented output:\n%s", data)
+	}
+
+	if pkg.Files[0].Content == "" || pkg.Functions[0].Body == "" || pkg.Types[0].Methods[0].Body == "" {
+		t.Errorf("Expected the package itself to be left unchanged")
+	}
+}
+
+func TestToJSONUnsupported(t *testing.T) {
+	if _, err := toJSON(GFPFunction{}, GFPJSONOptions{}); err == nil {
+		t.Errorf("Expected an error for an unsupported type")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := jsonSchema()
+	if err != nil {
+		t.Fatalf("jsonSchema failed: %v", err)
+	}
+
+	var schema struct {
+		Schema string `json:"$schema"`
+		Ref    string `json:"$ref"`
+		Defs   map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if schema.Schema != jsonSchemaDialect || schema.Ref != "#/$defs/GFPDocument" {
+		t.Errorf("Unexpected schema header: %q, %q", schema.Schema, schema.Ref)
+	}
+
+	for _, name := range []string{"GFPDocument", "GFPGoFile", "GFPPackage", "GFPModule", "GFPCallGraph", "GFPImplementation", "GFPEnum", "GFPRange"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("Expected definition %s", name)
+		}
+	}
+
+	goFile := schema.Defs["GFPGoFile"]
+	if !reflect.DeepEqual(goFile.Required, []string{"fileName", "package"}) {
+		t.Errorf("Unexpected required properties of GFPGoFile: %v", goFile.Required)
+	}
+	functions := goFile.Properties["functions"]
+	if functions["type"] != "array" || functions["items"].(map[string]any)["$ref"] != "#/$defs/GFPFunction" {
+		t.Errorf("Unexpected schema for functions: %v", functions)
+	}
+	version := schema.Defs["GFPDocument"].Properties["schemaVersion"]
+	if version["const"] != float64(SchemaVersion) {
+		t.Errorf("Expected schemaVersion to be pinned, got %v", version)
+	}
+	if _, ok := schema.Defs["GFPCallGraph"].Properties["callers"]; ok {
+		t.Errorf("Expected unexported fields to be left out")
+	}
+}
+
+func mustParseSource(t *testing.T, name, src string) *GFPGoFile {
+	t.Helper()
+	goFile, err := NewParser(GFPOptions{}).parseGoSource(name, []byte(src))
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	return goFile
+}
@@ -0,0 +1,573 @@
+package gofileparser
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GFPMockOptions configures the code generated by GenerateMock.
+type GFPMockOptions struct {
+	// Package is the name of the package the mock is generated into. Empty generates it
+	// into the package of the interface.
+	Package string
+
+	// Name is the name of the mock type. Empty uses "Mock" followed by the interface name.
+	Name string
+}
+
+// mockGenerator generates the mock of a single interface.
+type mockGenerator struct {
+	iface    GFPInterface
+	pkg      *GFPPackage
+	name     string            // Name of the mock type
+	external bool              // Whether the mock is generated outside the interface's package
+	imports  map[string]string // Import paths by the names types refer to them with
+	locals   map[string]bool   // Names declared at the package level of the interface's package
+	params   map[string]bool   // Type parameters of the interface
+	used     map[string]string // Imports the generated code refers to, by name
+	importer *localImporter    // Importer for embedded interfaces of other packages, created on first use
+}
+
+// mockMethod is an interface method prepared for generation.
+type mockMethod struct {
+	name    string
+	params  []mockParam
+	results []string // Result types
+}
+
+// mockParam is a parameter of a mocked method.
+type mockParam struct {
+	name     string // Name in the generated method
+	field    string // Field of the call record
+	typ      string // Type, the element type for variadic parameters
+	variadic bool
+}
+
+// generateMock generates the gofmt'd source of a mock implementation of iface, which is
+// declared in pkg.
+func generateMock(iface GFPInterface, pkg *GFPPackage, opts GFPMockOptions) ([]byte, error) {
+	if pkg == nil {
+		return nil, fmt.Errorf("mock %s: the declaring package is required", iface.Name)
+	}
+	if len(iface.TypeSet) > 0 {
+		return nil, fmt.Errorf("mock %s: constraint interfaces cannot be implemented", iface.Name)
+	}
+	g := &mockGenerator{
+		iface:   iface,
+		pkg:     pkg,
+		name:    opts.Name,
+		imports: make(map[string]string),
+		locals:  make(map[string]bool),
+		params:  make(map[string]bool),
+		used:    make(map[string]string),
+	}
+	if g.name == "" {
+		g.name = "Mock" + iface.Name
+	}
+	packageName := opts.Package
+	if packageName == "" {
+		packageName = pkg.Name
+	}
+	g.external = packageName != pkg.Name
+	if g.external && pkg.ImportPath == "" {
+		return nil, fmt.Errorf("mock %s: package %s has no import path to refer to it from package %s", iface.Name, pkg.Name, packageName)
+	}
+	g.collectNames()
+
+	methods, err := g.methodSet()
+	if err != nil {
+		return nil, err
+	}
+	members := map[string]bool{"mu": true, "calls": true}
+	var prepared []mockMethod
+	for _, method := range methods {
+		if g.external && !token.IsExported(method.Name) {
+			return nil, fmt.Errorf("mock %s: unexported method %s cannot be implemented outside package %s", iface.Name, method.Name, pkg.Name)
+		}
+		for _, member := range []string{method.Name + "Func", method.Name + "Calls"} {
+			if members[member] {
+				return nil, fmt.Errorf("mock %s: method %s conflicts with the generated member %s", iface.Name, method.Name, member)
+			}
+			members[member] = true
+		}
+		m, err := g.prepareMethod(method)
+		if err != nil {
+			return nil, err
+		}
+		prepared = append(prepared, m)
+	}
+	for _, method := range methods {
+		if members[method.Name] {
+			return nil, fmt.Errorf("mock %s: method %s conflicts with a generated member", iface.Name, method.Name)
+		}
+	}
+
+	typeParams, typeArgs, err := g.typeParams()
+	if err != nil {
+		return nil, err
+	}
+	if g.used["sync"] != "" && g.used["sync"] != "sync" {
+		return nil, fmt.Errorf("mock %s: import name sync is already used for %q", iface.Name, g.used["sync"])
+	}
+	g.used["sync"] = "sync"
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gofileparser. DO NOT EDIT.\n\npackage %s\n\n", packageName)
+	g.writeImports(&buf)
+
+	ifaceRef := iface.Name
+	if g.external {
+		ifaceRef = pkg.Name + "." + iface.Name
+	}
+	mockType := g.name + typeArgs
+	fmt.Fprintf(&buf, "// %s is a mock implementation of %s. Calls are recorded, and the\n", g.name, ifaceRef)
+	fmt.Fprintf(&buf, "// results are taken from the function fields, or are zero values if they are nil.\n")
+	fmt.Fprintf(&buf, "type %s%s struct {\n", g.name, typeParams)
+	for _, m := range prepared {
+		fmt.Fprintf(&buf, "// %sFunc is called by %s.\n%sFunc func(%s) %s\n\n", m.name, m.name, m.name, m.signature(), m.resultList())
+	}
+	buf.WriteString("mu sync.Mutex\ncalls struct {\n")
+	for _, m := range prepared {
+		fmt.Fprintf(&buf, "%s []%s%sCall%s\n", m.name, g.name, exportedName(m.name), typeArgs)
+	}
+	buf.WriteString("}\n}\n\n")
+
+	if typeParams == "" && (!g.external || token.IsExported(iface.Name)) {
+		fmt.Fprintf(&buf, "var _ %s = (*%s)(nil)\n\n", ifaceRef, g.name)
+	}
+
+	for _, m := range prepared {
+		record := g.name + exportedName(m.name) + "Call"
+		fmt.Fprintf(&buf, "// %s records a call of %s.%s.\ntype %s%s struct {\n", record, g.name, m.name, record, typeParams)
+		for _, p := range m.params {
+			typ := p.typ
+			if p.variadic {
+				typ = "[]" + typ
+			}
+			fmt.Fprintf(&buf, "%s %s\n", p.field, typ)
+		}
+		buf.WriteString("}\n\n")
+
+		fmt.Fprintf(&buf, "// %s records the call and calls %sFunc.\n", m.name, m.name)
+		fmt.Fprintf(&buf, "func (m *%s) %s(%s) %s {\n", mockType, m.name, m.signature(), m.resultList())
+		buf.WriteString("m.mu.Lock()\n")
+		fmt.Fprintf(&buf, "m.calls.%s = append(m.calls.%s, %s%s{", m.name, m.name, record, typeArgs)
+		for i, p := range m.params {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%s: %s", p.field, p.name)
+		}
+		fmt.Fprintf(&buf, "})\nfn := m.%sFunc\nm.mu.Unlock()\n", m.name)
+		if len(m.results) == 0 {
+			fmt.Fprintf(&buf, "if fn != nil {\nfn(%s)\n}\n}\n\n", m.arguments())
+		} else {
+			buf.WriteString("if fn == nil {\n")
+			var zeros []string
+			for i, result := range m.results {
+				fmt.Fprintf(&buf, "var r%d %s\n", i, result)
+				zeros = append(zeros, "r"+strconv.Itoa(i))
+			}
+			fmt.Fprintf(&buf, "return %s\n}\nreturn fn(%s)\n}\n\n", strings.Join(zeros, ", "), m.arguments())
+		}
+
+		fmt.Fprintf(&buf, "// %sCalls returns the calls of %s in the order they were made.\n", m.name, m.name)
+		fmt.Fprintf(&buf, "func (m *%s) %sCalls() []%s%s {\n", mockType, m.name, record, typeArgs)
+		fmt.Fprintf(&buf, "m.mu.Lock()\ndefer m.mu.Unlock()\nreturn append([]%s%s(nil), m.calls.%s...)\n}\n\n", record, typeArgs, m.name)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("mock %s: formatting generated code: %w", iface.Name, err)
+	}
+	return src, nil
+}
+
+// collectNames records the imports of the interface's package and the names declared at
+// its package level. Imports of the file declaring the interface take precedence over
+// those of other files that use the same name for a different path.
+func (g *mockGenerator) collectNames() {
+	add := func(imports []GFPImport, override bool) {
+		for _, imp := range imports {
+			importPath := unquote(imp.Path)
+			name := imp.Name
+			if name == "" {
+				name = defaultImportName(importPath)
+			}
+			if name == "_" || name == "." {
+				continue
+			}
+			if _, ok := g.imports[name]; !ok || override {
+				g.imports[name] = importPath
+			}
+		}
+	}
+	add(g.pkg.Imports, false)
+	for _, file := range g.pkg.Files {
+		for _, candidate := range file.Interfaces {
+			if candidate.Name == g.iface.Name && candidate.Line == g.iface.Line {
+				add(file.Imports, true)
+			}
+		}
+	}
+
+	for _, t := range g.pkg.Types {
+		g.locals[t.Name] = true
+	}
+	for _, iface := range g.pkg.Interfaces {
+		g.locals[iface.Name] = true
+	}
+	for _, c := range g.pkg.Constants {
+		g.locals[c.Name] = true
+	}
+	for _, tp := range g.iface.TypeParams {
+		g.params[tp.Name] = true
+	}
+}
+
+// methodSet returns the methods of the interface, including those of the interfaces it
+// embeds. Embedded interfaces of the same package are expanded from their declarations,
+// those of other packages from their type-checked method sets. Embedded generic interfaces
+// and interfaces that cannot be loaded are reported as an error, since their methods could
+// not be implemented.
+func (g *mockGenerator) methodSet() ([]GFPInterfaceMethod, error) {
+	var methods []GFPInterfaceMethod
+	seen := make(map[string]bool)
+	visiting := make(map[string]bool)
+	add := func(method GFPInterfaceMethod) {
+		if !seen[method.Name] {
+			seen[method.Name] = true
+			methods = append(methods, method)
+		}
+	}
+
+	var collect func(iface GFPInterface) error
+	collect = func(iface GFPInterface) error {
+		if visiting[iface.Name] {
+			return nil
+		}
+		visiting[iface.Name] = true
+		for _, method := range iface.Methods {
+			add(method)
+		}
+		for _, embed := range iface.Embeds {
+			if strings.Contains(embed, "[") {
+				return fmt.Errorf("mock %s: cannot expand the embedded generic interface %s", g.iface.Name, embed)
+			}
+			if strings.Contains(embed, ".") {
+				embedded, err := g.importedMethods(embed)
+				if err != nil {
+					return fmt.Errorf("mock %s: cannot expand the embedded interface %s: %w", g.iface.Name, embed, err)
+				}
+				for _, method := range embedded {
+					add(method)
+				}
+				continue
+			}
+			embedded, ok := resolveEmbed(embed, typeScope{pkg: g.pkg}, nil)
+			if !ok {
+				return fmt.Errorf("mock %s: cannot expand the embedded interface %s: not declared in package %s", g.iface.Name, embed, g.pkg.Name)
+			}
+			if err := collect(embedded.iface); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(g.iface); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// importedMethods returns the methods of an interface of another package, such as
+// "io.ReadCloser", type-checked from the standard library or the module of the interface's
+// package. Their types are written with the import names of g, adding imports for
+// packages that the interface's package does not import.
+func (g *mockGenerator) importedMethods(embed string) ([]GFPInterfaceMethod, error) {
+	qualifier, name, _ := strings.Cut(embed, ".")
+	importPath, ok := g.imports[qualifier]
+	if !ok {
+		return nil, fmt.Errorf("package %s is not imported", qualifier)
+	}
+	if g.importer == nil {
+		var module *goModule
+		if g.pkg.Dir != "" {
+			module = findDiskModule(g.pkg.Dir)
+		}
+		g.importer = newLocalImporter(token.NewFileSet(), module, build.Default)
+	}
+	iface, err := importedInterface(g.importer, importPath, name)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if fn := iface.Method(i); !fn.Exported() {
+			return nil, fmt.Errorf("unexported method %s cannot be implemented", fn.Name())
+		}
+	}
+
+	return interfaceMethods(iface, func(p *types.Package) string {
+		if p.Path() == g.pkg.ImportPath {
+			return ""
+		}
+		return g.importName(p.Path(), p.Name())
+	}), nil
+}
+
+// importName returns the name types of the package with the given path are referred to
+// by. It is the name the interface's package imports it under, or else the package name,
+// numbered if the name is taken by another import.
+func (g *mockGenerator) importName(importPath, pkgName string) string {
+	for name, path := range g.imports {
+		if path == importPath {
+			return name
+		}
+	}
+	name := pkgName
+	for i := 2; g.imports[name] != ""; i++ {
+		name = pkgName + strconv.Itoa(i)
+	}
+	g.imports[name] = importPath
+	return name
+}
+
+// prepareMethod qualifies the types of a method and names its parameters. Parameters that
+// are unnamed, blank, or whose names would shadow an identifier the generated method uses
+// are renamed to p0, p1 and so on.
+func (g *mockGenerator) prepareMethod(method GFPInterfaceMethod) (mockMethod, error) {
+	m := mockMethod{name: method.Name}
+	reserved := map[string]bool{"m": true, "fn": true}
+	for i := range method.Results {
+		reserved["r"+strconv.Itoa(i)] = true
+	}
+
+	for _, p := range method.Parameters {
+		typ, err := g.qualify(p.Type)
+		if err != nil {
+			return m, err
+		}
+		m.params = append(m.params, mockParam{name: p.Name, typ: typ, variadic: p.Variadic})
+	}
+	for _, r := range method.Results {
+		typ, err := g.qualify(r.Type)
+		if err != nil {
+			return m, err
+		}
+		m.results = append(m.results, typ)
+	}
+	for _, typ := range append(paramTypes(m.params), m.results...) {
+		for _, ident := range typeIdents(typ) {
+			reserved[ident] = true
+		}
+	}
+
+	names := make(map[string]bool)
+	fields := make(map[string]bool)
+	for i := range m.params {
+		p := &m.params[i]
+		if p.name == "" || p.name == "_" || reserved[p.name] || names[p.name] {
+			p.name = "p" + strconv.Itoa(i)
+			for reserved[p.name] || names[p.name] {
+				p.name += "_"
+			}
+		}
+		names[p.name] = true
+
+		p.field = exportedName(p.name)
+		if fields[p.field] {
+			p.field = "P" + strconv.Itoa(i)
+		}
+		fields[p.field] = true
+	}
+	return m, nil
+}
+
+// typeParams returns the type parameter list of the mock type, with qualified constraints,
+// and the matching type argument list, or empty strings if the interface is not generic.
+func (g *mockGenerator) typeParams() (string, string, error) {
+	if len(g.iface.TypeParams) == 0 {
+		return "", "", nil
+	}
+	var params, args []string
+	for _, tp := range g.iface.TypeParams {
+		constraint, err := g.qualify(tp.Constraint)
+		if err != nil {
+			return "", "", err
+		}
+		params = append(params, tp.Name+" "+constraint)
+		args = append(args, tp.Name)
+	}
+	return "[" + strings.Join(params, ", ") + "]", "[" + strings.Join(args, ", ") + "]", nil
+}
+
+// qualify rewrites a type expression for the package of the mock: names declared in the
+// interface's package are qualified with its name if the mock is generated elsewhere. The
+// imports the expression refers to are recorded in g.used.
+func (g *mockGenerator) qualify(typ string) (string, error) {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return "", fmt.Errorf("mock %s: cannot parse type %q: %w", g.iface.Name, typ, err)
+	}
+
+	var inspectErr error
+	skip := make(map[*ast.Ident]bool)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if inspectErr != nil {
+			return false
+		}
+		switch x := n.(type) {
+		case *ast.Field:
+			for _, name := range x.Names {
+				skip[name] = true
+			}
+		case *ast.SelectorExpr:
+			qualifier, ok := x.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			importPath, ok := g.imports[qualifier.Name]
+			if !ok {
+				inspectErr = fmt.Errorf("mock %s: unknown package %s in type %q", g.iface.Name, qualifier.Name, typ)
+				return false
+			}
+			inspectErr = g.use(qualifier.Name, importPath)
+			return false
+		case *ast.Ident:
+			if skip[x] || !g.external || g.params[x.Name] || !g.locals[x.Name] {
+				return true
+			}
+			if !token.IsExported(x.Name) {
+				inspectErr = fmt.Errorf("mock %s: unexported type %s cannot be referred to outside package %s", g.iface.Name, x.Name, g.pkg.Name)
+				return false
+			}
+			inspectErr = g.use(g.pkg.Name, g.pkg.ImportPath)
+			x.Name = g.pkg.Name + "." + x.Name
+		}
+		return true
+	})
+	if inspectErr != nil {
+		return "", inspectErr
+	}
+	return exprToString(expr), nil
+}
+
+// use records that the generated code refers to the import path by name.
+func (g *mockGenerator) use(name, importPath string) error {
+	if used, ok := g.used[name]; ok && used != importPath {
+		return fmt.Errorf("mock %s: import name %s refers to both %q and %q", g.iface.Name, name, used, importPath)
+	}
+	g.used[name] = importPath
+	return nil
+}
+
+// writeImports writes the import declaration of the used imports, standard library
+// packages first.
+func (g *mockGenerator) writeImports(buf *bytes.Buffer) {
+	var std, other []string
+	for name, importPath := range g.used {
+		spec := strconv.Quote(importPath)
+		if name != defaultImportName(importPath) {
+			spec = name + " " + spec
+		}
+		if isStandardImportPath(importPath) {
+			std = append(std, spec)
+		} else {
+			other = append(other, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	buf.WriteString("import (\n")
+	for _, spec := range std {
+		fmt.Fprintf(buf, "%s\n", spec)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, spec := range other {
+		fmt.Fprintf(buf, "%s\n", spec)
+	}
+	buf.WriteString(")\n\n")
+}
+
+// signature returns the parameter list of the method.
+func (m mockMethod) signature() string {
+	var params []string
+	for _, p := range m.params {
+		typ := p.typ
+		if p.variadic {
+			typ = "..." + typ
+		}
+		params = append(params, p.name+" "+typ)
+	}
+	return strings.Join(params, ", ")
+}
+
+// resultList returns the result list of the method, parenthesized if needed.
+func (m mockMethod) resultList() string {
+	switch len(m.results) {
+	case 0:
+		return ""
+	case 1:
+		return m.results[0]
+	}
+	return "(" + strings.Join(m.results, ", ") + ")"
+}
+
+// arguments returns the arguments passing the parameters on to the function field.
+func (m mockMethod) arguments() string {
+	var args []string
+	for _, p := range m.params {
+		arg := p.name
+		if p.variadic {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, ", ")
+}
+
+// paramTypes returns the types of params.
+func paramTypes(params []mockParam) []string {
+	types := make([]string, 0, len(params))
+	for _, p := range params {
+		types = append(types, p.typ)
+	}
+	return types
+}
+
+// typeIdents returns the identifiers a type expression refers to, including package
+// qualifiers.
+func typeIdents(typ string) []string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return nil
+	}
+	var idents []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			idents = append(idents, ident.Name)
+		}
+		return true
+	})
+	return idents
+}
+
+// exportedName returns name with its first letter in upper case.
+func exportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,177 @@
+package gofileparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mockSource = `package store
+
+import (
+	"context"
+	stdio "io"
+)
+
+type Item struct{ ID string }
+
+type Closer interface {
+	Close() error
+}
+
+// Store stores items.
+type Store interface {
+	Closer
+	stdio.Reader
+	stdio.WriterTo
+	Get(ctx context.Context, ids ...string) ([]Item, error)
+	Put(Item, func(m Item) bool)
+	Reset()
+}
+
+type Cache[K comparable, V any] interface {
+	Load(key K) (V, bool)
+	Store(key K, values ...V)
+}
+
+type internal interface {
+	get(m map[string]Item) (fn int)
+}
+
+type Number interface {
+	~int | ~float64
+}
+
+type Getter[T any] interface {
+	Get() T
+}
+`
+
+// mockErrorSource adds interfaces to mockSource that cannot be mocked.
+const mockErrorSource = mockSource + `
+type Strings interface {
+	Getter[string]
+}
+
+type Unknown interface {
+	stdio.Missing
+}
+`
+
+func TestGenerateMock(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	dir := filepath.Join(root, "store")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	createTempGoFile(t, dir, "store.go", mockSource)
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+	interfaces := make(map[string]GFPInterface)
+	for _, iface := range pkg.Interfaces {
+		interfaces[iface.Name] = iface
+	}
+
+	tests := []struct {
+		iface    string
+		opts     GFPMockOptions
+		dir      string
+		file     string
+		contains []string
+	}{
+		{"Store", GFPMockOptions{}, dir, "mock_store.go", []string{
+			"package store",
+			"\tstdio \"io\"",
+			"func (m *MockStore) Read(p []byte) (int, error) {",
+			"func (m *MockStore) WriteTo(w stdio.Writer) (int64, error) {",
+			"GetFunc func(ctx context.Context, ids ...string) ([]Item, error)",
+			"func (m *MockStore) Close() error {",
+			"func (m *MockStore) Put(p0 Item, p1 func(m Item) bool) {",
+			"type MockStoreGetCall struct {\n\tCtx context.Context\n\tIds []string\n}",
+			"return fn(ctx, ids...)",
+			"var _ Store = (*MockStore)(nil)",
+		}},
+		{"Store", GFPMockOptions{Package: "storemock", Name: "Store"}, filepath.Join(root, "storemock"), "store.go", []string{
+			"package storemock",
+			"\"example.com/mod/store\"",
+			"GetFunc func(ctx context.Context, ids ...string) ([]store.Item, error)",
+			"var _ store.Store = (*Store)(nil)",
+		}},
+		{"Cache", GFPMockOptions{}, dir, "mock_cache.go", []string{
+			"type MockCache[K comparable, V any] struct {",
+			"func (m *MockCache[K, V]) Store(key K, values ...V) {",
+			"func (m *MockCache[K, V]) LoadCalls() []MockCacheLoadCall[K, V] {",
+		}},
+		{"internal", GFPMockOptions{}, dir, "mock_internal.go", []string{
+			"func (m *Mockinternal) get(p0 map[string]Item) int {",
+		}},
+	}
+
+	for _, tt := range tests {
+		src, err := GenerateMock(interfaces[tt.iface], pkg, tt.opts)
+		if err != nil {
+			t.Fatalf("GenerateMock(%s) failed: %v", tt.iface, err)
+		}
+		for _, s := range tt.contains {
+			if !strings.Contains(string(src), s) {
+				t.Errorf("Expected the mock of %s to contain %q, got:\n%s", tt.iface, s, src)
+			}
+		}
+		if err := os.MkdirAll(tt.dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		createTempGoFile(t, tt.dir, tt.file, string(src))
+	}
+
+	// The generated files must compile along with the package they mock.
+	for _, d := range []string{dir, filepath.Join(root, "storemock")} {
+		checked, err := NewParser(GFPOptions{TypeCheck: true}).parseGoPackage(context.Background(), d)
+		if err != nil {
+			t.Fatalf("parseGoPackage failed: %v", err)
+		}
+		for _, file := range checked.Files {
+			for _, diag := range file.Diagnostics {
+				t.Errorf("Type error in generated code: %s:%d:%d: %s", diag.File, diag.Line, diag.Column, diag.Message)
+			}
+		}
+	}
+}
+
+func TestGenerateMockErrors(t *testing.T) {
+	goFile := mustParseSource(t, "store.go", mockErrorSource)
+	pkg := &GFPPackage{Name: "store", ImportPath: "example.com/mod/store", Imports: goFile.Imports,
+		Types: goFile.Types, Interfaces: goFile.Interfaces, Files: []*GFPGoFile{goFile}}
+	interfaces := make(map[string]GFPInterface)
+	for _, iface := range pkg.Interfaces {
+		interfaces[iface.Name] = iface
+	}
+
+	tests := []struct {
+		name  string
+		iface GFPInterface
+		pkg   *GFPPackage
+		opts  GFPMockOptions
+		err   string
+	}{
+		{"constraint", interfaces["Number"], pkg, GFPMockOptions{}, "constraint interfaces"},
+		{"unexported method", interfaces["internal"], pkg, GFPMockOptions{Package: "other"}, "unexported method get"},
+		{"unknown import", GFPInterface{Name: "Bad", Methods: []GFPInterfaceMethod{{Name: "Do", Parameters: []GFPParameter{{Type: "bytes.Buffer"}}}}}, pkg, GFPMockOptions{}, "unknown package bytes"},
+		{"conflict", GFPInterface{Name: "Bad", Methods: []GFPInterfaceMethod{{Name: "Do"}, {Name: "DoFunc"}}}, pkg, GFPMockOptions{}, "conflicts"},
+		{"no package", interfaces["Store"], nil, GFPMockOptions{}, "declaring package is required"},
+		{"generic embed", interfaces["Strings"], pkg, GFPMockOptions{}, "cannot expand the embedded generic interface Getter[string]"},
+		{"unknown embed", interfaces["Unknown"], pkg, GFPMockOptions{}, "cannot expand the embedded interface stdio.Missing"},
+	}
+
+	for _, tt := range tests {
+		_, err := GenerateMock(tt.iface, tt.pkg, tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package gofileparser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// modulePackage is a package directory found while walking a module.
+type modulePackage struct {
+	dir        string   // Directory of the package
+	importPath string   // Import path of the package
+	files      []string // Paths of the Go files to parse
+}
+
+// parseGoModule walks every package below the go.mod in root and returns a GFP_Module structure.
+// This is the internal implementation of ParseGoModule. Only the tree below start, which
+// is root or a directory inside the module, is walked; the module holds the packages
+// found there.
+//
+// The tree is walked first, then the files of all packages are parsed in a single pool of
+// workers, so that many small packages still keep every worker busy. Type checking and
+// the aggregation into packages happen afterwards, one package at a time.
+func (p *GFPParser) parseGoModule(ctx context.Context, root, start string) (*GFPModule, error) {
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading go.mod: %w", err)
+	}
+
+	module := &GFPModule{
+		Dir:      root,
+		Packages: make(map[string]*GFPPackage),
+	}
+	module.Path, module.GoVersion, err = parseModFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing go.mod: %w", err)
+	}
+
+	var pkgs []modulePackage
+	var files []string
+	err = filepath.WalkDir(start, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if dir != root && dir != start {
+			if skipModuleDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			// Nested modules are not part of this module.
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		names, err := p.packageFiles(dir)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		importPath := module.Path
+		if rel != "." {
+			importPath = path.Join(module.Path, filepath.ToSlash(rel))
+		}
+
+		pkgs = append(pkgs, modulePackage{dir: dir, importPath: importPath, files: names})
+		files = append(files, names...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	parsedFiles, astFiles, err := p.parseGoFiles(ctx, fset, files, os.ReadFile)
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	errs := []error{err}
+
+	var importer *localImporter
+	if p.opts.TypeCheck {
+		importer = newLocalImporter(fset, &goModule{fsys: os.DirFS(root), path: module.Path}, p.buildContext())
+	}
+
+	for _, mp := range pkgs {
+		n := len(mp.files)
+		pkgFiles, pkgASTs := parsedOnly(parsedFiles[:n], astFiles[:n])
+		parsedFiles, astFiles = parsedFiles[n:], astFiles[n:]
+		if len(pkgFiles) == 0 {
+			// Every file failed; the errors are already recorded.
+			continue
+		}
+
+		if importer != nil {
+			typeCheckPackage(importer, mp.importPath, pkgASTs, pkgFiles)
+		}
+
+		pkg, err := newPackage(mp.dir, mp.importPath, pkgFiles)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		module.Packages[mp.importPath] = pkg
+	}
+
+	return module, errors.Join(errs...)
+}
+
+// parseGoModuleTree parses the packages in the tree below dir of the module enclosing it.
+// This is the internal implementation of GFPParser.ParseModuleTree.
+func (p *GFPParser) parseGoModuleTree(ctx context.Context, dir string) (*GFPModule, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, _, ok := findModule(filepath.ToSlash(absDir), func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.FromSlash(name))
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside a Go module", dir)
+	}
+	return p.parseGoModule(ctx, filepath.FromSlash(root), absDir)
+}
+
+// sortedPackages returns the packages of the module ordered by import path.
+func (m *GFPModule) sortedPackages() []*GFPPackage {
+	pkgs := make([]*GFPPackage, 0, len(m.Packages))
+	for _, pkg := range m.Packages {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].ImportPath < pkgs[j].ImportPath
+	})
+	return pkgs
+}
+
+// skipModuleDir reports whether the go tool ignores a directory with the given name
+// when matching packages: testdata, vendor and names starting with "." or "_".
+func skipModuleDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// parseModFile extracts the module path and Go version from the contents of a go.mod file.
+func parseModFile(content []byte) (modulePath, goVersion string, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			modulePath = fields[1]
+			if unquoted, err := strconv.Unquote(modulePath); err == nil {
+				modulePath = unquoted
+			}
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	if modulePath == "" {
+		return "", "", fmt.Errorf("no module directive found")
+	}
+	return modulePath, goVersion, nil
+}
@@ -0,0 +1,180 @@
+package gofileparser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGoModule(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod // the module\n\ngo 1.21\n")
+	createTempGoFile(t, root, "root.go", "package mod\n\nfunc Root() {}\n")
+	for _, dir := range []string{"api/v1", "internal", "testdata", "vendor/dep", ".hidden", "_skip", "nested", "empty"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+	}
+	createTempGoFile(t, filepath.Join(root, "api/v1"), "api.go", "package v1\n\nfunc API() {}\n")
+	createTempGoFile(t, filepath.Join(root, "internal"), "internal.go", "package internal\n")
+	createTempGoFile(t, filepath.Join(root, "testdata"), "data.go", "package testdata\n")
+	createTempGoFile(t, filepath.Join(root, "vendor/dep"), "dep.go", "package dep\n")
+	createTempGoFile(t, filepath.Join(root, ".hidden"), "hidden.go", "package hidden\n")
+	createTempGoFile(t, filepath.Join(root, "_skip"), "skip.go", "package skip\n")
+	createTempGoFile(t, filepath.Join(root, "nested"), "go.mod", "module example.com/nested\n")
+	createTempGoFile(t, filepath.Join(root, "nested"), "nested.go", "package nested\n")
+	createTempGoFile(t, filepath.Join(root, "empty"), "empty_test.go", "package empty\n")
+
+	module, err := NewParser(GFPOptions{}).parseGoModule(context.Background(), root, root)
+	if err != nil {
+		t.Fatalf("parseGoModule failed: %v", err)
+	}
+
+	if module.Path != "example.com/mod" || module.GoVersion != "1.21" {
+		t.Errorf("Expected module example.com/mod at go 1.21, got %s at go %s", module.Path, module.GoVersion)
+	}
+
+	expected := map[string]string{
+		"example.com/mod":          "mod",
+		"example.com/mod/api/v1":   "v1",
+		"example.com/mod/internal": "internal",
+	}
+	if len(module.Packages) != len(expected) {
+		t.Errorf("Expected %d packages, got %d: %v", len(expected), len(module.Packages), module.Packages)
+	}
+	for importPath, name := range expected {
+		pkg, ok := module.Packages[importPath]
+		if !ok {
+			t.Errorf("Package %s not found", importPath)
+			continue
+		}
+		if pkg.Name != name || pkg.ImportPath != importPath {
+			t.Errorf("Package %s not parsed correctly: %+v", importPath, pkg)
+		}
+	}
+
+	if pkg := module.Packages["example.com/mod/api/v1"]; pkg != nil && pkg.Dir != filepath.Join(root, "api/v1") {
+		t.Errorf("Expected dir %s, got %s", filepath.Join(root, "api/v1"), pkg.Dir)
+	}
+}
+
+func TestParseGoModuleErrors(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	for _, dir := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+	}
+	createTempGoFile(t, filepath.Join(root, "a"), "a.go", "package a\n\nfunc {\n")
+	createTempGoFile(t, filepath.Join(root, "b"), "b.go", "package b\n\nvar = 1\n")
+	createTempGoFile(t, filepath.Join(root, "b"), "b2.go", "package b\n\nvar B = 1\n")
+	createTempGoFile(t, filepath.Join(root, "c"), "c.go", "package c\n\nfunc C() {}\n")
+
+	module, err := NewParser(GFPOptions{Workers: 2}).parseGoModule(context.Background(), root, root)
+	if err == nil {
+		t.Fatalf("Expected an error for broken files")
+	}
+	if module == nil || len(module.Packages) != 2 {
+		t.Fatalf("Expected the packages b and c along with the error, got %+v", module)
+	}
+	if b := module.Packages["example.com/mod/b"]; b == nil || len(b.Variables) != 1 || b.Variables[0].Name != "B" {
+		t.Errorf("Expected package b with the variable of b2.go, got %+v", b)
+	}
+	if c := module.Packages["example.com/mod/c"]; c == nil || len(c.Functions) != 1 {
+		t.Errorf("Expected package c, got %+v", c)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected the error to mention %s, got %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewParser(GFPOptions{}).parseGoModule(ctx, root, root); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseGoModuleWithoutGoMod(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewParser(GFPOptions{}).parseGoModule(context.Background(), dir, dir); err == nil {
+		t.Errorf("Expected an error for a directory without go.mod")
+	}
+}
+
+func TestParseGoModuleTree(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	createTempGoFile(t, root, "root.go", "package mod\n")
+	for _, dir := range []string{"api/v1/testdata", "bad"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+	}
+	createTempGoFile(t, filepath.Join(root, "api"), "api.go", "package api\n")
+	createTempGoFile(t, filepath.Join(root, "api/v1"), "v1.go", "package v1\n")
+	createTempGoFile(t, filepath.Join(root, "api/v1/testdata"), "data.go", "package testdata\n")
+	createTempGoFile(t, filepath.Join(root, "bad"), "bad.go", "package bad\n\nfunc {\n")
+
+	module, err := NewParser(GFPOptions{}).parseGoModuleTree(context.Background(), filepath.Join(root, "api"))
+	if err != nil {
+		t.Fatalf("parseGoModuleTree failed: %v", err)
+	}
+	if module.Path != "example.com/mod" || module.Dir != root {
+		t.Errorf("Expected the module example.com/mod in %s, got %s in %s", root, module.Path, module.Dir)
+	}
+	if len(module.Packages) != 2 || module.Packages["example.com/mod/api"] == nil || module.Packages["example.com/mod/api/v1"] == nil {
+		t.Errorf("Expected only the packages below api, got %v", module.Packages)
+	}
+
+	if _, err := NewParser(GFPOptions{}).parseGoModuleTree(context.Background(), root); err == nil || !strings.Contains(err.Error(), "bad.go") {
+		t.Errorf("Expected the error of bad.go when parsing the whole tree, got %v", err)
+	}
+	if _, err := NewParser(GFPOptions{}).parseGoModuleTree(context.Background(), t.TempDir()); err == nil {
+		t.Errorf("Expected an error for a directory outside of a module")
+	}
+}
+
+func TestParseModFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		modulePath string
+		goVersion  string
+		wantErr    bool
+	}{
+		{
+			name:       "Plain",
+			content:    "module github.com/a/b\n\ngo 1.22.1\n\nrequire x.y/z v1.0.0\n",
+			modulePath: "github.com/a/b",
+			goVersion:  "1.22.1",
+		},
+		{
+			name:       "Quoted path without go directive",
+			content:    "module \"github.com/a/b\"\n",
+			modulePath: "github.com/a/b",
+		},
+		{
+			name:    "Missing module directive",
+			content: "go 1.21\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modulePath, goVersion, err := parseModFile([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseModFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if modulePath != tt.modulePath || goVersion != tt.goVersion {
+				t.Errorf("parseModFile() = %v, %v, want %v, %v", modulePath, goVersion, tt.modulePath, tt.goVersion)
+			}
+		})
+	}
+}
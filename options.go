@@ -0,0 +1,77 @@
+package gofileparser
+
+import "go/build"
+
+// GFPOptions configures how a GFPParser parses Go sources.
+// The zero value parses the way the package-level Parse functions do.
+type GFPOptions struct {
+	// Tolerant parses in error-tolerant mode. Syntax errors no longer abort parsing;
+	// every declaration that could be recovered is returned, and the errors are
+	// reported in GFPGoFile.Diagnostics instead.
+	Tolerant bool
+
+	// TypeCheck type-checks the parsed sources with go/types and annotates parameters,
+	// results and struct fields with resolved type information (GFPTypeInfo). Imports are
+	// resolved from GOROOT sources and from the enclosing module only, so nothing is ever
+	// downloaded. Type errors are reported in GFPGoFile.Diagnostics and never fail parsing.
+	TypeCheck bool
+
+	// Filter reports whether a Go file found in a package directory should be parsed.
+	// It is called with the file's path (slash-separated within an fs.FS) after test files
+	// have been excluded, unless IncludeTests is set. A nil Filter parses every file.
+	// Files passed to ParseFile, ParseSource or ParseReader are never filtered.
+	Filter func(path string) bool
+
+	// Workers is the maximum number of files parsed concurrently when parsing a package
+	// or module. Zero or less uses runtime.GOMAXPROCS(0). Results are returned in the
+	// same order regardless of the number of workers.
+	Workers int
+
+	// Cache, if not nil, is consulted before a file is parsed and receives every newly
+	// parsed file, so that unchanged files are only parsed once. Entries are keyed by
+	// file name, content hash and parser version, and results of the Tolerant and
+	// AllComments modes are kept apart. The cache is not used in TypeCheck mode, which needs the syntax trees.
+	Cache *GFPCache
+
+	// BuildContext selects the files of a package by GOOS, GOARCH, build tags and cgo
+	// support, evaluating file name suffixes such as "_windows.go" and //go:build lines
+	// like the go tool does. Nil uses build.Default, i.e. the host platform and the GOOS,
+	// GOARCH and CGO_ENABLED environment variables. Files passed to ParseFile, ParseSource
+	// or ParseReader are never excluded.
+	BuildContext *build.Context
+
+	// IncludeTests also parses the _test.go files of a package directory. Files of the
+	// external test package (package foo_test) are kept in GFPPackage.Files and their tests
+	// in GFPPackage.Tests, but their declarations are not merged into the symbol tables of
+	// the package under test. Test files are always parsed when passed to ParseFile,
+	// ParseSource or ParseReader.
+	IncludeTests bool
+
+	// AllComments reports every comment of a file in GFPGoFile.Comments, classified as a
+	// doc comment, trailing line comment or comment in the body of a declaration. By
+	// default only free-floating comments are reported, as the others are already
+	// available through the Doc and Comment fields of the declarations.
+	AllComments bool
+}
+
+// GFPParser parses Go sources according to a fixed set of GFPOptions.
+// A GFPParser is safe for concurrent use.
+type GFPParser struct {
+	opts GFPOptions
+}
+
+// NewParser returns a GFPParser that parses with the given options.
+//
+// Parameters:
+//   - opts: GFPOptions - The options to parse with.
+//
+// Returns:
+//   - *GFPParser: A pointer to the new parser.
+func NewParser(opts GFPOptions) *GFPParser {
+	return &GFPParser{opts: opts}
+}
+
+// Options returns the options the parser was created with.
+func (p *GFPParser) Options() GFPOptions {
+	return p.opts
+}
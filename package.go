@@ -0,0 +1,147 @@
+package gofileparser
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrNoGoFiles is returned (wrapped) when a package directory contains no Go files to parse,
+// e.g. because it only has test files and GFPOptions.IncludeTests is not set, or every
+// file was excluded by GFPOptions.Filter.
+var ErrNoGoFiles = errors.New("no Go files")
+
+// newPackage aggregates the parsed files of one directory into a GFP_Package structure.
+//
+// Parameters:
+//   - dir: string - The directory the files were read from.
+//   - importPath: string - The import path of the package (may be empty if unknown).
+//   - files: []*GFP_GoFile - The parsed files, in the order they should be merged.
+//
+// Returns:
+//   - *GFP_Package: A pointer to the aggregated package.
+//   - error: An error if there are no files or the files declare different packages.
+//
+// Symbol tables of all files are merged in file order, and every method is attached to
+// the type it is declared on, regardless of which file declares the type. Files of the
+// external test package only contribute their tests, as they declare a different package.
+func newPackage(dir, importPath string, files []*GFPGoFile) (*GFPPackage, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoGoFiles, dir)
+	}
+
+	// The package under test names the package. A directory with only external test
+	// files is named after, and merges the symbols of, their package.
+	first := files[0]
+	for _, file := range files {
+		if file.TestKind != "external" {
+			first = file
+			break
+		}
+	}
+	evaluatePackageConstants(files, first.Package)
+
+	pkg := &GFPPackage{
+		Name:       first.Package,
+		ImportPath: importPath,
+		Dir:        dir,
+		Files:      files,
+	}
+
+	seenImports := make(map[GFPImport]bool)
+	for _, file := range files {
+		pkg.Tests = append(pkg.Tests, file.Tests...)
+		if file.TestKind == "external" && file.Package == pkg.Name+"_test" {
+			continue
+		}
+		if file.Package != pkg.Name {
+			return nil, fmt.Errorf("found packages %s (%s) and %s (%s) in %s",
+				pkg.Name, first.FileName, file.Package, file.FileName, dir)
+		}
+
+		if file.FileDoc != "" && (pkg.Doc == "" || path.Base(file.FileName) == "doc.go") {
+			pkg.Doc = file.FileDoc
+			pkg.DocComment = file.FileDocComment
+		}
+
+		for _, imp := range file.Imports {
+			key := GFPImport{Path: imp.Path, Name: imp.Name}
+			if !seenImports[key] {
+				seenImports[key] = true
+				pkg.Imports = append(pkg.Imports, imp)
+			}
+		}
+		pkg.Constants = append(pkg.Constants, file.Constants...)
+		pkg.Variables = append(pkg.Variables, file.Variables...)
+		pkg.Types = append(pkg.Types, file.Types...)
+		pkg.Functions = append(pkg.Functions, file.Functions...)
+		pkg.Methods = append(pkg.Methods, file.Methods...)
+		pkg.Interfaces = append(pkg.Interfaces, file.Interfaces...)
+	}
+
+	typeIndex := make(map[string]int, len(pkg.Types))
+	for i, t := range pkg.Types {
+		typeIndex[t.Name] = i
+	}
+	for i, method := range pkg.Methods {
+		if j, ok := typeIndex[receiverTypeName(method.Receiver)]; ok {
+			pkg.Methods[i].TypeParams = receiverConstraints(method.TypeParams, pkg.Types[j].TypeParams)
+			pkg.Types[j].Methods = append(pkg.Types[j].Methods, pkg.Methods[i])
+		}
+	}
+
+	return pkg, nil
+}
+
+// receiverTypeName returns the name of the type a method receiver refers to,
+// stripping any pointer and type parameters (e.g. "*List[T]" becomes "List").
+func receiverTypeName(receiver string) string {
+	name := strings.TrimPrefix(receiver, "*")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(name)
+}
+
+// receiverConstraints returns a copy of the receiver type parameters of a method with the
+// constraints taken, by position, from the type parameters of the receiver type.
+func receiverConstraints(params, typeParams []GFPTypeParam) []GFPTypeParam {
+	if len(params) == 0 || len(params) != len(typeParams) {
+		return params
+	}
+	result := make([]GFPTypeParam, len(params))
+	for i, param := range params {
+		result[i] = typeParams[i]
+		result[i].Name = param.Name
+	}
+	return result
+}
+
+// findModule searches a slash-separated directory and its parents for a go.mod file and
+// returns the directory containing it together with the declared module path.
+func findModule(dir string, readFile func(name string) ([]byte, error)) (root, modulePath string, ok bool) {
+	for {
+		if content, err := readFile(path.Join(dir, "go.mod")); err == nil {
+			modulePath, _, err := parseModFile(content)
+			if err != nil {
+				return "", "", false
+			}
+			return dir, modulePath, true
+		}
+		parent := path.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// moduleImportPath returns the import path of a slash-separated directory inside the
+// module with the given path rooted at root.
+func moduleImportPath(modulePath, root, dir string) string {
+	if root == "." {
+		return path.Join(modulePath, dir)
+	}
+	return path.Join(modulePath, strings.TrimPrefix(strings.TrimPrefix(dir, root), "/"))
+}
@@ -0,0 +1,128 @@
+package gofileparser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPackage(t *testing.T) {
+	tempDir := t.TempDir()
+	createTempGoFile(t, tempDir, "a.go", `// Package shapes has a doc comment outside doc.go.
+package shapes
+
+import "fmt"
+
+type Square struct{ Side int }
+
+func (l *List[T]) Len() int { return 0 }
+
+func Describe() string { return fmt.Sprint(Square{}) }
+`)
+	createTempGoFile(t, tempDir, "doc.go", "// Package shapes provides shapes.\npackage shapes\n")
+	createTempGoFile(t, tempDir, "z.go", `package shapes
+
+import "fmt"
+
+type List[T any] struct{ items []T }
+
+func (s Square) Area() int { return s.Side * s.Side }
+
+func (s *Square) String() string { return fmt.Sprint(s.Side) }
+`)
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), tempDir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	if pkg.Name != "shapes" || len(pkg.Files) != 3 {
+		t.Errorf("Package not parsed correctly: %s with %d files", pkg.Name, len(pkg.Files))
+	}
+
+	if pkg.Doc != "Package shapes provides shapes.\n" {
+		t.Errorf("Expected doc from doc.go, got %q", pkg.Doc)
+	}
+
+	if len(pkg.Imports) != 1 || pkg.Imports[0].Path != "\"fmt\"" {
+		t.Errorf("Expected imports to be merged without duplicates, got %v", pkg.Imports)
+	}
+
+	if len(pkg.Types) != 2 || len(pkg.Functions) != 1 || len(pkg.Methods) != 3 {
+		t.Fatalf("Symbols not merged correctly: %d types, %d functions, %d methods",
+			len(pkg.Types), len(pkg.Functions), len(pkg.Methods))
+	}
+
+	methods := make(map[string][]string)
+	for _, typ := range pkg.Types {
+		for _, method := range typ.Methods {
+			methods[typ.Name] = append(methods[typ.Name], method.Name)
+		}
+	}
+	if strings.Join(methods["Square"], ",") != "Area,String" {
+		t.Errorf("Expected Square methods Area,String, got %v", methods["Square"])
+	}
+	if strings.Join(methods["List"], ",") != "Len" {
+		t.Errorf("Expected List methods Len, got %v", methods["List"])
+	}
+
+	if len(pkg.Files[2].Types) != 1 || len(pkg.Files[2].Types[0].Methods) != 0 {
+		t.Errorf("File level types should not have methods attached")
+	}
+}
+
+func TestNewPackageErrors(t *testing.T) {
+	if _, err := newPackage("empty", "", nil); !errors.Is(err, ErrNoGoFiles) {
+		t.Errorf("Expected ErrNoGoFiles for a package without files, got %v", err)
+	}
+
+	files := []*GFPGoFile{
+		{FileName: "a.go", Package: "a"},
+		{FileName: "b.go", Package: "b"},
+	}
+	if _, err := newPackage("mixed", "", files); err == nil {
+		t.Errorf("Expected an error for mixed package names")
+	}
+}
+
+func TestParseGoPackageImportPath(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/root\n")
+	dir := filepath.Join(root, "sub", "pkg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	createTempGoFile(t, dir, "pkg.go", "package pkg\n")
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	if pkg.ImportPath != "example.com/root/sub/pkg" {
+		t.Errorf("Expected import path 'example.com/root/sub/pkg', got '%s'", pkg.ImportPath)
+	}
+}
+
+func TestReceiverTypeName(t *testing.T) {
+	tests := []struct {
+		receiver string
+		expected string
+	}{
+		{"T", "T"},
+		{"*T", "T"},
+		{"List[T]", "List"},
+		{"*Map[K, V]", "Map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.receiver, func(t *testing.T) {
+			if result := receiverTypeName(tt.receiver); result != tt.expected {
+				t.Errorf("receiverTypeName() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,38 +1,104 @@
 package gofileparser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"sort"
+	"sync"
 )
 
 // parseGoFile parses a Go source file and returns a GFP_GoFile structure.
 // This is the internal implementation of ParseGoFile.
-func parseGoFile(filePath string) (*GFPGoFile, error) {
+func (p *GFPParser) parseGoFile(filePath string) (*GFPGoFile, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
+	var module *goModule
+	if p.opts.TypeCheck {
+		module = findDiskModule(filepath.Dir(filePath))
+	}
+	return p.parseSingleSource(filePath, content, module)
+}
+
+// parseGoReader reads Go source from r and parses it under the given name.
+// This is the internal implementation of ParseGoReader.
+func (p *GFPParser) parseGoReader(name string, r io.Reader) (*GFPGoFile, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return p.parseGoSource(name, content)
+}
+
+// parseGoSource parses Go source held in memory and returns a GFP_GoFile structure.
+// The name is used for position information and recorded as the file name.
+func (p *GFPParser) parseGoSource(name string, content []byte) (*GFPGoFile, error) {
+	return p.parseSingleSource(name, content, nil)
+}
+
+// parseSingleSource parses a file on its own. In type-checked mode the file is checked as a
+// single-file package, resolving imports from the standard library and module, if not nil.
+func (p *GFPParser) parseSingleSource(name string, content []byte, module *goModule) (*GFPGoFile, error) {
 	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
+	goFile, file, err := p.parseCached(fset, name, content)
 	if err != nil {
 		return nil, err
 	}
 
+	if p.opts.TypeCheck {
+		typeCheckPackage(newLocalImporter(fset, module, p.buildContext()), "", []*ast.File{file}, []*GFPGoFile{goFile})
+	}
+
+	return goFile, nil
+}
+
+// parseSource parses Go source into fset and returns both the GFP_GoFile structure and the AST it was built from.
+func (p *GFPParser) parseSource(fset *token.FileSet, name string, content []byte) (*GFPGoFile, *ast.File, error) {
+	mode := parser.ParseComments
+	if p.opts.Tolerant {
+		mode |= parser.AllErrors
+	}
+
+	file, err := parser.ParseFile(fset, name, content, mode)
+	var diagnostics []GFPDiagnostic
+	if err != nil {
+		errList, ok := err.(scanner.ErrorList)
+		if !p.opts.Tolerant || !ok || file == nil {
+			return nil, nil, err
+		}
+		diagnostics = parseDiagnostics(errList)
+	}
+
 	goFile := &GFPGoFile{}
 
+	goFile.FileName = name
 	goFile.Package = file.Name.Name
 	goFile.Content = string(content)
 
 	if file.Doc != nil {
 		goFile.FileDoc = file.Doc.Text()
 	}
+	goFile.BuildConstraint = parseBuildConstraint(file)
+	goFile.TestKind = testFileKind(name, goFile.Package)
+	if goFile.TestKind != "" {
+		goFile.Tests = parseTests(fset, file)
+	}
 
+	imports := importNames(file.Imports)
 	for _, decl := range file.Decls {
 		switch d := decl.(type) {
 		case *ast.GenDecl:
@@ -48,38 +114,207 @@ func parseGoFile(filePath string) (*GFPGoFile, error) {
 			}
 		case *ast.FuncDecl:
 			if d.Recv == nil {
-				goFile.Functions = append(goFile.Functions, parseFunction(fset, d))
+				fn := parseFunction(fset, d)
+				fn.Calls = parseCalls(fset, d, imports)
+				goFile.Functions = append(goFile.Functions, fn)
 			} else {
-				goFile.Methods = append(goFile.Methods, parseMethod(fset, d))
+				method := parseMethod(fset, d)
+				method.Calls = parseCalls(fset, d, imports)
+				goFile.Methods = append(goFile.Methods, method)
 			}
 		}
 	}
 
-	goFile.Comments = parseComments(fset, file)
+	annotateDocComments(goFile, imports)
+	// The following passes walk file.Decls again and rely on the order in which the loop
+	// above added the declarations to goFile to find their entries.
+	annotateDirectives(fset, file, goFile)
+	evaluateConstants(file, goFile)
+	goFile.Comments = parseComments(fset, file, p.opts.AllComments)
+	goFile.Diagnostics = diagnostics
 
-	return goFile, nil
+	return goFile, file, nil
 }
 
-// parseGoPackage parses all Go files in a directory and returns a slice of GFP_GoFile structures.
-func parseGoPackage(dirPath string) ([]*GFPGoFile, error) {
+// parseGoPackage parses all Go files in a directory and returns a GFP_Package structure.
+func (p *GFPParser) parseGoPackage(ctx context.Context, dirPath string) (*GFPPackage, error) {
+	names, err := p.packageFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	files, astFiles, parseErr := p.parseGoFiles(ctx, fset, names, os.ReadFile)
+	if files, astFiles = parsedOnly(files, astFiles); len(files) == 0 && parseErr != nil {
+		return nil, parseErr
+	}
+
+	importPath := ""
+	var module *goModule
+	if absDir, err := filepath.Abs(dirPath); err == nil {
+		root, modulePath, ok := findModule(filepath.ToSlash(absDir), func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.FromSlash(name))
+		})
+		if ok {
+			importPath = moduleImportPath(modulePath, root, filepath.ToSlash(absDir))
+			module = &goModule{fsys: os.DirFS(filepath.FromSlash(root)), path: modulePath}
+		}
+	}
+
+	if p.opts.TypeCheck && len(files) > 0 {
+		typeCheckPackage(newLocalImporter(fset, module, p.buildContext()), importPath, astFiles, files)
+	}
+
+	pkg, err := newPackage(dirPath, importPath, files)
+	if err != nil {
+		return nil, errors.Join(parseErr, err)
+	}
+	return pkg, parseErr
+}
+
+// packageFiles returns the paths of the Go files in a directory that belong to its package.
+func (p *GFPParser) packageFiles(dirPath string) ([]string, error) {
 	files, err := filepath.Glob(filepath.Join(dirPath, "*.go"))
 	if err != nil {
 		return nil, fmt.Errorf("error finding Go files: %w", err)
 	}
 
-	var parsedFiles []*GFPGoFile
+	ctx := p.buildContext()
+	return p.selectGoFiles(&ctx, files)
+}
+
+// parseGoPackageFS parses all Go files in a directory of fsys and returns a GFP_Package structure.
+func (p *GFPParser) parseGoPackageFS(ctx context.Context, fsys fs.FS, dir string) (*GFPPackage, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("error finding Go files: %w", err)
+	}
+
+	readFile := func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}
+	buildCtx := fsBuildContext(p.buildContext(), fsys)
+	files, err = p.selectGoFiles(&buildCtx, files)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	parsedFiles, astFiles, parseErr := p.parseGoFiles(ctx, fset, files, readFile)
+	if parsedFiles, astFiles = parsedOnly(parsedFiles, astFiles); len(parsedFiles) == 0 && parseErr != nil {
+		return nil, parseErr
+	}
+
+	dir = path.Clean(dir)
+	importPath := ""
+	var module *goModule
+	if root, modulePath, ok := findModule(dir, readFile); ok {
+		importPath = moduleImportPath(modulePath, root, dir)
+		if sub, err := fs.Sub(fsys, root); err == nil {
+			module = &goModule{fsys: sub, path: modulePath}
+		}
+	}
+
+	if p.opts.TypeCheck && len(parsedFiles) > 0 {
+		typeCheckPackage(newLocalImporter(fset, module, p.buildContext()), importPath, astFiles, parsedFiles)
+	}
+
+	pkg, err := newPackage(dir, importPath, parsedFiles)
+	if err != nil {
+		return nil, errors.Join(parseErr, err)
+	}
+	return pkg, parseErr
+}
+
+// selectGoFiles returns the Go files among files that match the build context and pass
+// the filter of the options. Test files are only included if the options ask for them.
+func (p *GFPParser) selectGoFiles(buildCtx *build.Context, files []string) ([]string, error) {
+	var selected []string
 	for _, file := range files {
-		// Skip test files
-		if filepath.Ext(file) == ".go" && !isTestFile(file) {
-			parsedFile, err := ParseGoFile(file)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing file %s: %w", file, err)
+		if filepath.Ext(file) != ".go" || (isTestFile(file) && !p.opts.IncludeTests) {
+			continue
+		}
+		match, err := matchBuildContext(buildCtx, file)
+		if err != nil {
+			return nil, fmt.Errorf("error matching build constraints of %s: %w", file, err)
+		}
+		if match && (p.opts.Filter == nil || p.opts.Filter(file)) {
+			selected = append(selected, file)
+		}
+	}
+	return selected, nil
+}
+
+// parseGoFiles reads each of the named files with readFile and parses it into fset.
+//
+// The files are parsed concurrently by up to GFPOptions.Workers goroutines, and the results
+// are returned in the order of files. A file that cannot be read or parsed does not stop the
+// others: its results are nil, and the errors of all such files are joined and returned
+// along with the results of the others. If ctx is cancelled, no further files are started
+// and only ctx.Err() is returned.
+func (p *GFPParser) parseGoFiles(ctx context.Context, fset *token.FileSet, files []string, readFile func(name string) ([]byte, error)) ([]*GFPGoFile, []*ast.File, error) {
+	parsedFiles := make([]*GFPGoFile, len(files))
+	astFiles := make([]*ast.File, len(files))
+	errs := make([]error, len(files))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < p.workers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				content, err := readFile(files[i])
+				if err != nil {
+					errs[i] = fmt.Errorf("error reading file %s: %w", files[i], err)
+					continue
+				}
+				parsedFiles[i], astFiles[i], err = p.parseCached(fset, files[i], content)
+				if err != nil {
+					errs[i] = fmt.Errorf("error parsing file %s: %w", files[i], err)
+				}
 			}
-			parsedFiles = append(parsedFiles, parsedFile)
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	return parsedFiles, nil
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return parsedFiles, astFiles, errors.Join(errs...)
+}
+
+// parsedOnly returns the files of a parseGoFiles result that were parsed, dropping the nil
+// entries of files that failed.
+func parsedOnly(parsedFiles []*GFPGoFile, astFiles []*ast.File) ([]*GFPGoFile, []*ast.File) {
+	var goFiles []*GFPGoFile
+	var files []*ast.File
+	for i, goFile := range parsedFiles {
+		if goFile != nil {
+			goFiles = append(goFiles, goFile)
+			files = append(files, astFiles[i])
+		}
+	}
+	return goFiles, files
+}
+
+// workers returns the number of goroutines used to parse the given number of files.
+func (p *GFPParser) workers(files int) int {
+	workers := p.opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return min(workers, files)
 }
 
 // parseImports extracts import declarations from a GenDecl.
@@ -88,8 +323,9 @@ func parseImports(fset *token.FileSet, decl *ast.GenDecl) []GFPImport {
 	for _, spec := range decl.Specs {
 		if is, ok := spec.(*ast.ImportSpec); ok {
 			imp := GFPImport{
-				Path: is.Path.Value,
-				Line: fset.Position(is.Pos()).Line,
+				Path:  is.Path.Value,
+				Line:  fset.Position(is.Pos()).Line,
+				Range: specRange(fset, decl, is),
 			}
 			if is.Name != nil {
 				imp.Name = is.Name.Name
@@ -100,17 +336,29 @@ func parseImports(fset *token.FileSet, decl *ast.GenDecl) []GFPImport {
 	return imports
 }
 
-// parseConstants extracts constant declarations from a GenDecl.
+// parseConstants extracts constant declarations from a GenDecl. Constants whose spec
+// omits the values get the type of the previous spec, as implied by Go's implicit
+// repetition in iota blocks.
 func parseConstants(fset *token.FileSet, decl *ast.GenDecl) []GFPConstant {
 	var constants []GFPConstant
+	var typ ast.Expr
 	for _, spec := range decl.Specs {
 		if vs, ok := spec.(*ast.ValueSpec); ok {
+			// A spec without values repeats the type and values of the previous one.
+			if len(vs.Values) > 0 {
+				typ = vs.Type
+			}
+			doc := specDoc(decl, vs.Doc)
 			for i, name := range vs.Names {
 				c := GFPConstant{
-					Name: name.Name,
-					Type: exprToString(vs.Type),
-					Doc:  vs.Doc.Text(),
-					Line: fset.Position(name.Pos()).Line,
+					Name:     name.Name,
+					Type:     exprToString(typ),
+					Doc:      doc.Text(),
+					GroupDoc: groupDoc(decl),
+					Comment:  vs.Comment.Text(),
+					Line:     fset.Position(name.Pos()).Line,
+					Range:    specRange(fset, decl, vs),
+					DocRange: commentRange(fset, doc),
 				}
 				if i < len(vs.Values) {
 					c.Value = exprToString(vs.Values[i])
@@ -127,12 +375,17 @@ func parseVariables(fset *token.FileSet, decl *ast.GenDecl) []GFPVariable {
 	var variables []GFPVariable
 	for _, spec := range decl.Specs {
 		if vs, ok := spec.(*ast.ValueSpec); ok {
+			doc := specDoc(decl, vs.Doc)
 			for i, name := range vs.Names {
 				v := GFPVariable{
-					Name: name.Name,
-					Type: exprToString(vs.Type),
-					Doc:  vs.Doc.Text(),
-					Line: fset.Position(name.Pos()).Line,
+					Name:     name.Name,
+					Type:     exprToString(vs.Type),
+					Doc:      doc.Text(),
+					GroupDoc: groupDoc(decl),
+					Comment:  vs.Comment.Text(),
+					Line:     fset.Position(name.Pos()).Line,
+					Range:    specRange(fset, decl, vs),
+					DocRange: commentRange(fset, doc),
 				}
 				if i < len(vs.Values) {
 					v.Value = exprToString(vs.Values[i])
@@ -144,12 +397,32 @@ func parseVariables(fset *token.FileSet, decl *ast.GenDecl) []GFPVariable {
 	return variables
 }
 
+// specDoc returns the documentation of a spec in a const, var or type declaration: its
+// own doc comment or, if it has none and is the only spec of the declaration, the doc
+// comment of the declaration. go/parser attaches the comment of an unparenthesized
+// declaration such as "type T int" to the declaration, not the spec.
+func specDoc(decl *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && len(decl.Specs) == 1 {
+		return decl.Doc
+	}
+	return doc
+}
+
+// groupDoc returns the doc comment of a parenthesized declaration group such as
+// "const ( ... )", or "" for an unparenthesized declaration.
+func groupDoc(decl *ast.GenDecl) string {
+	if !decl.Lparen.IsValid() {
+		return ""
+	}
+	return decl.Doc.Text()
+}
+
 // parseTypes extracts type declarations from a GenDecl and adds them to the GFP_GoFile.
 func parseTypes(fset *token.FileSet, decl *ast.GenDecl, goFile *GFPGoFile) {
 	for _, spec := range decl.Specs {
 		if ts, ok := spec.(*ast.TypeSpec); ok {
 			if _, ok := ts.Type.(*ast.InterfaceType); ok {
-				goFile.Interfaces = append(goFile.Interfaces, parseInterface(fset, ts))
+				goFile.Interfaces = append(goFile.Interfaces, parseInterface(fset, ts, decl))
 			} else {
 				goFile.Types = append(goFile.Types, parseType(fset, ts, decl))
 			}
@@ -159,24 +432,91 @@ func parseTypes(fset *token.FileSet, decl *ast.GenDecl, goFile *GFPGoFile) {
 
 // parseType extracts a single type definition from a TypeSpec.
 func parseType(fset *token.FileSet, ts *ast.TypeSpec, decl *ast.GenDecl) GFPType {
-	return GFPType{
-		Name: ts.Name.Name,
-		Def:  exprToString(ts.Type),
-		Doc:  decl.Doc.Text(),
-		Line: fset.Position(ts.Name.Pos()).Line,
+	doc := specDoc(decl, ts.Doc)
+	t := GFPType{
+		Name:       ts.Name.Name,
+		TypeParams: parseTypeParams(ts.TypeParams),
+		Def:        exprToString(ts.Type),
+		Alias:      ts.Assign.IsValid(),
+		Doc:        doc.Text(),
+		GroupDoc:   groupDoc(decl),
+		Comment:    ts.Comment.Text(),
+		Line:       fset.Position(ts.Name.Pos()).Line,
+		Range:      specRange(fset, decl, ts),
+		DocRange:   commentRange(fset, doc),
+	}
+	if st, ok := ts.Type.(*ast.StructType); ok {
+		t.Fields = parseFields(fset, st.Fields)
+	}
+	return t
+}
+
+// parseFields extracts struct field definitions from a FieldList.
+func parseFields(fset *token.FileSet, fields *ast.FieldList) []GFPField {
+	var result []GFPField
+	if fields == nil {
+		return result
+	}
+	for _, field := range fields.List {
+		base := GFPField{
+			Type:     exprToString(field.Type),
+			Doc:      field.Doc.Text(),
+			Comment:  field.Comment.Text(),
+			Range:    nodeRange(fset, field),
+			DocRange: commentRange(fset, field.Doc),
+		}
+		if field.Tag != nil {
+			base.Tag = unquote(field.Tag.Value)
+			base.Tags = parseStructTag(base.Tag)
+		}
+		if len(field.Names) == 0 {
+			f := base
+			f.Name = embeddedFieldName(field.Type)
+			f.Embedded = true
+			f.Exported = ast.IsExported(f.Name)
+			f.Line = fset.Position(field.Type.Pos()).Line
+			result = append(result, f)
+			continue
+		}
+		for _, name := range field.Names {
+			f := base
+			f.Name = name.Name
+			f.Exported = name.IsExported()
+			f.Line = fset.Position(name.Pos()).Line
+			result = append(result, f)
+		}
 	}
+	return result
 }
 
 // parseInterface extracts an interface definition from a TypeSpec.
-func parseInterface(fset *token.FileSet, ts *ast.TypeSpec) GFPInterface {
+func parseInterface(fset *token.FileSet, ts *ast.TypeSpec, decl *ast.GenDecl) GFPInterface {
+	doc := specDoc(decl, ts.Doc)
 	iface := GFPInterface{
-		Name: ts.Name.Name,
-		Doc:  ts.Doc.Text(),
-		Line: fset.Position(ts.Name.Pos()).Line,
+		Name:       ts.Name.Name,
+		TypeParams: parseTypeParams(ts.TypeParams),
+		Doc:        doc.Text(),
+		GroupDoc:   groupDoc(decl),
+		Comment:    ts.Comment.Text(),
+		Line:       fset.Position(ts.Name.Pos()).Line,
+		Range:      specRange(fset, decl, ts),
+		DocRange:   commentRange(fset, doc),
 	}
-	if it, ok := ts.Type.(*ast.InterfaceType); ok {
-		for _, method := range it.Methods.List {
-			iface.Methods = append(iface.Methods, parseInterfaceMethod(fset, method))
+	if it, ok := ts.Type.(*ast.InterfaceType); ok && it.Methods != nil {
+		for _, elem := range it.Methods.List {
+			switch {
+			case len(elem.Names) > 0:
+				if _, ok := elem.Type.(*ast.FuncType); ok {
+					iface.Methods = append(iface.Methods, parseInterfaceMethod(fset, elem))
+				}
+			case isEmbeddedInterface(elem.Type):
+				iface.Embeds = append(iface.Embeds, exprToString(elem.Type))
+			default:
+				iface.TypeSet = append(iface.TypeSet, GFPTypeSetEntry{
+					Terms: parseTypeTerms(elem.Type),
+					Line:  fset.Position(elem.Type.Pos()).Line,
+				})
+			}
 		}
 	}
 	return iface
@@ -184,11 +524,15 @@ func parseInterface(fset *token.FileSet, ts *ast.TypeSpec) GFPInterface {
 
 // parseInterfaceMethod extracts a method definition from an interface field.
 func parseInterfaceMethod(fset *token.FileSet, field *ast.Field) GFPInterfaceMethod {
+	funcType := field.Type.(*ast.FuncType)
 	method := GFPInterfaceMethod{
 		Name:       field.Names[0].Name,
-		Parameters: parseParameters(field.Type.(*ast.FuncType).Params),
-		ReturnType: parseReturnType(field.Type.(*ast.FuncType).Results),
+		Parameters: parseParameters(funcType.Params),
+		Results:    parseParameters(funcType.Results),
+		Doc:        field.Doc.Text(),
 		Line:       fset.Position(field.Names[0].Pos()).Line,
+		Range:      nodeRange(fset, field),
+		DocRange:   commentRange(fset, field.Doc),
 	}
 	return method
 }
@@ -197,36 +541,78 @@ func parseInterfaceMethod(fset *token.FileSet, field *ast.Field) GFPInterfaceMet
 func parseFunction(fset *token.FileSet, decl *ast.FuncDecl) GFPFunction {
 	return GFPFunction{
 		Name:       decl.Name.Name,
+		TypeParams: parseTypeParams(decl.Type.TypeParams),
 		Parameters: parseParameters(decl.Type.Params),
-		ReturnType: parseReturnType(decl.Type.Results),
+		Results:    parseParameters(decl.Type.Results),
 		Body:       blockStmtToString(decl.Body),
 		Doc:        decl.Doc.Text(),
 		Line:       fset.Position(decl.Name.Pos()).Line,
+		Range:      nodeRange(fset, decl),
+		DocRange:   commentRange(fset, decl.Doc),
+		BodyRange:  bodyRange(fset, decl.Body),
 	}
 }
 
 // parseMethod extracts a method definition from a FuncDecl.
 func parseMethod(fset *token.FileSet, decl *ast.FuncDecl) GFPMethod {
+	var recv ast.Expr
+	if len(decl.Recv.List) > 0 {
+		recv = decl.Recv.List[0].Type
+	}
 	return GFPMethod{
-		Receiver:   exprToString(decl.Recv.List[0].Type),
+		Receiver:   exprToString(recv),
 		Name:       decl.Name.Name,
+		TypeParams: parseReceiverTypeParams(recv),
 		Parameters: parseParameters(decl.Type.Params),
-		ReturnType: parseReturnType(decl.Type.Results),
+		Results:    parseParameters(decl.Type.Results),
 		Body:       blockStmtToString(decl.Body),
 		Doc:        decl.Doc.Text(),
 		Line:       fset.Position(decl.Name.Pos()).Line,
+		Range:      nodeRange(fset, decl),
+		DocRange:   commentRange(fset, decl.Doc),
+		BodyRange:  bodyRange(fset, decl.Body),
 	}
 }
 
-// parseParameters extracts parameter definitions from a FieldList.
+// parseParameters extracts parameter or result definitions from a FieldList.
+// Unnamed parameters are kept with an empty name.
 func parseParameters(fields *ast.FieldList) []GFPParameter {
 	var params []GFPParameter
 	if fields != nil {
 		for _, field := range fields.List {
+			param := GFPParameter{Type: exprToString(field.Type)}
+			if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
+				param.Type = exprToString(ellipsis.Elt)
+				param.Variadic = true
+			}
+			if len(field.Names) == 0 {
+				params = append(params, param)
+				continue
+			}
 			for _, name := range field.Names {
-				params = append(params, GFPParameter{
-					Name: name.Name,
-					Type: exprToString(field.Type),
+				param.Name = name.Name
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
+// parseTypeParams extracts type parameter definitions from a FieldList.
+func parseTypeParams(fields *ast.FieldList) []GFPTypeParam {
+	var params []GFPTypeParam
+	if fields != nil {
+		for _, field := range fields.List {
+			var terms []GFPTypeTerm
+			switch field.Type.(type) {
+			case *ast.BinaryExpr, *ast.UnaryExpr:
+				terms = parseTypeTerms(field.Type)
+			}
+			for _, name := range field.Names {
+				params = append(params, GFPTypeParam{
+					Name:       name.Name,
+					Constraint: exprToString(field.Type),
+					Terms:      terms,
 				})
 			}
 		}
@@ -234,31 +620,174 @@ func parseParameters(fields *ast.FieldList) []GFPParameter {
 	return params
 }
 
-// parseReturnType extracts return type(s) from a FieldList.
-func parseReturnType(fields *ast.FieldList) string {
-	if fields == nil || len(fields.List) == 0 {
-		return ""
+// parseReceiverTypeParams extracts the type parameter names of a generic receiver such as "*List[T]".
+// The constraints are declared on the receiver type and are therefore left empty.
+func parseReceiverTypeParams(recv ast.Expr) []GFPTypeParam {
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
 	}
-	var types []string
-	for _, field := range fields.List {
-		types = append(types, exprToString(field.Type))
+	var indices []ast.Expr
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{r.Index}
+	case *ast.IndexListExpr:
+		indices = r.Indices
 	}
-	if len(types) == 1 {
-		return types[0]
+	var params []GFPTypeParam
+	for _, index := range indices {
+		params = append(params, GFPTypeParam{Name: exprToString(index)})
 	}
-	return "(" + strings.Join(types, ", ") + ")"
+	return params
 }
 
-// parseComments extracts comments from a File that are not associated with declarations.
-func parseComments(fset *token.FileSet, file *ast.File) []GFPComment {
+// parseTypeTerms flattens a type union such as "~int | string" into its terms.
+func parseTypeTerms(expr ast.Expr) []GFPTypeTerm {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.OR {
+			return append(parseTypeTerms(e.X), parseTypeTerms(e.Y)...)
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.TILDE {
+			return []GFPTypeTerm{{Type: exprToString(e.X), Tilde: true}}
+		}
+	case *ast.ParenExpr:
+		return parseTypeTerms(e.X)
+	}
+	return []GFPTypeTerm{{Type: exprToString(expr)}}
+}
+
+// parseDiagnostics converts the syntax errors reported by go/parser into diagnostics.
+func parseDiagnostics(errList scanner.ErrorList) []GFPDiagnostic {
+	diagnostics := make([]GFPDiagnostic, 0, len(errList))
+	for _, e := range errList {
+		diagnostics = append(diagnostics, GFPDiagnostic{
+			File:    e.Pos.Filename,
+			Line:    e.Pos.Line,
+			Column:  e.Pos.Column,
+			Message: e.Msg,
+		})
+	}
+	return diagnostics
+}
+
+// parseComments extracts the comments of a File. Unless all is set, only free-floating
+// comments are returned, i.e. neither doc comments, trailing line comments nor comments
+// inside a declaration.
+func parseComments(fset *token.FileSet, file *ast.File, all bool) []GFPComment {
+	classes := classifyComments(fset, file)
+
 	var comments []GFPComment
 	for _, commentGroup := range file.Comments {
+		class := classes[commentGroup]
+		if class.kind == "" {
+			class.kind = "free"
+		}
+		if class.kind != "free" && !all {
+			continue
+		}
 		for _, comment := range commentGroup.List {
 			comments = append(comments, GFPComment{
 				Text: comment.Text,
 				Line: fset.Position(comment.Pos()).Line,
+				Kind: class.kind,
+				Decl: class.decl,
 			})
 		}
 	}
 	return comments
 }
+
+// commentClass describes how a comment group relates to the declarations of a file.
+type commentClass struct {
+	kind string // doc, trailing or body; empty for free-floating comments
+	decl string // Name of the declaration the comment belongs to
+}
+
+// classifyComments classifies the comment groups of a file that belong to a declaration:
+// the doc comments of the file and of declarations, specs, fields and interface methods,
+// their trailing line comments, and any other comment within a function or spec, which is
+// in the body of that declaration. Groups missing from the result are free-floating.
+func classifyComments(fset *token.FileSet, file *ast.File) map[*ast.CommentGroup]commentClass {
+	classes := make(map[*ast.CommentGroup]commentClass)
+	set := func(group *ast.CommentGroup, kind, decl string) {
+		if group != nil {
+			classes[group] = commentClass{kind: kind, decl: decl}
+		}
+	}
+	// body marks every comment within node that is not yet classified.
+	body := func(node ast.Node, decl string) {
+		for _, group := range commentsWithin(file, node) {
+			if _, ok := classes[group]; !ok {
+				classes[group] = commentClass{kind: "body", decl: decl}
+			}
+		}
+	}
+	fields := func(list *ast.FieldList, decl string) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			set(field.Doc, "doc", decl)
+			set(field.Comment, "trailing", decl)
+		}
+	}
+
+	set(file.Doc, "doc", "")
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				var name string
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					name = s.Names[0].Name
+					set(s.Doc, "doc", name)
+					set(s.Comment, "trailing", name)
+				case *ast.TypeSpec:
+					name = s.Name.Name
+					set(s.Doc, "doc", name)
+					set(s.Comment, "trailing", name)
+					switch t := s.Type.(type) {
+					case *ast.StructType:
+						fields(t.Fields, name)
+					case *ast.InterfaceType:
+						fields(t.Methods, name)
+					}
+				case *ast.ImportSpec:
+					set(s.Doc, "doc", "")
+					set(s.Comment, "trailing", "")
+					continue
+				}
+				if len(d.Specs) == 1 {
+					set(d.Doc, "doc", name)
+				}
+				body(spec, name)
+			}
+			// The doc comment of a group of several specs documents no single one of them.
+			if len(d.Specs) != 1 {
+				set(d.Doc, "doc", "")
+			}
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverTypeName(exprToString(d.Recv.List[0].Type)) + "." + name
+			}
+			set(d.Doc, "doc", name)
+			body(d, name)
+		}
+	}
+	return classes
+}
+
+// commentsWithin returns the comment groups of a file that lie within node. Since
+// file.Comments is sorted by position, they are found by binary search.
+func commentsWithin(file *ast.File, node ast.Node) []*ast.CommentGroup {
+	comments := file.Comments
+	start := sort.Search(len(comments), func(i int) bool { return comments[i].Pos() > node.Pos() })
+	end := start
+	for end < len(comments) && comments[end].End() <= node.End() {
+		end++
+	}
+	return comments[start:end]
+}
@@ -1,11 +1,17 @@
 package gofileparser
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestParseGoFile(t *testing.T) {
@@ -40,7 +46,7 @@ func (p Person) SayHello() {
 	}
 
 	// Parse the file
-	goFile, err := parseGoFile(tempFile)
+	goFile, err := NewParser(GFPOptions{}).parseGoFile(tempFile)
 	if err != nil {
 		t.Fatalf("ParseGoFile failed: %v", err)
 	}
@@ -87,12 +93,13 @@ func TestParseGoPackage(t *testing.T) {
 	createTempGoFile(t, tempDir, "file_test.go", "package main\n\nfunc TestFunc() {}\n")
 
 	// Parse the package
-	files, err := parseGoPackage(tempDir)
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), tempDir)
 	if err != nil {
 		t.Fatalf("ParseGoPackage failed: %v", err)
 	}
 
 	// Check parsed content
+	files := pkg.Files
 	if len(files) != 2 {
 		t.Errorf("Expected 2 files, got %d", len(files))
 	}
@@ -113,6 +120,187 @@ func TestParseGoPackage(t *testing.T) {
 	}
 }
 
+func TestParseGoPackageConcurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		createTempGoFile(t, tempDir, fmt.Sprintf("file%02d.go", i), fmt.Sprintf("package main\n\n// Func%02d is generated.\nfunc Func%02d() {}\n", i, i))
+	}
+
+	sequential, err := NewParser(GFPOptions{Workers: 1}).parseGoPackage(context.Background(), tempDir)
+	if err != nil {
+		t.Fatalf("parseGoPackage with 1 worker failed: %v", err)
+	}
+	concurrent, err := NewParser(GFPOptions{Workers: 8}).parseGoPackage(context.Background(), tempDir)
+	if err != nil {
+		t.Fatalf("parseGoPackage with 8 workers failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(sequential, concurrent) {
+		t.Errorf("Expected the same package regardless of the number of workers")
+	}
+	for i, fn := range concurrent.Functions {
+		if fn.Name != fmt.Sprintf("Func%02d", i) {
+			t.Errorf("Expected Func%02d at index %d, got %s", i, i, fn.Name)
+		}
+	}
+}
+
+func TestParseGoPackageErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	createTempGoFile(t, tempDir, "bad1.go", "package main\n\nfunc {\n")
+	createTempGoFile(t, tempDir, "good.go", "package main\n\nfunc Good() {}\n")
+	createTempGoFile(t, tempDir, "bad2.go", "package\n")
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), tempDir)
+	if err == nil {
+		t.Fatalf("Expected an error for broken files")
+	}
+	if pkg == nil || len(pkg.Files) != 1 || len(pkg.Functions) != 1 || pkg.Functions[0].Name != "Good" {
+		t.Errorf("Expected the package of good.go along with the error, got %+v", pkg)
+	}
+	for _, name := range []string{"bad1.go", "bad2.go"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected the error to mention %s, got %v", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewParser(GFPOptions{}).parseGoPackage(ctx, tempDir); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseGoSource(t *testing.T) {
+	src := []byte("package gen\n\n// Answer is generated.\nconst Answer = 42\n")
+
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("gen.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	if goFile.FileName != "gen.go" {
+		t.Errorf("Expected file name 'gen.go', got '%s'", goFile.FileName)
+	}
+
+	if goFile.Package != "gen" {
+		t.Errorf("Expected package 'gen', got '%s'", goFile.Package)
+	}
+
+	if len(goFile.Constants) != 1 || goFile.Constants[0].Value != "42" {
+		t.Errorf("Constant not parsed correctly")
+	}
+
+	if _, err := NewParser(GFPOptions{}).parseGoSource("broken.go", []byte("package")); err == nil {
+		t.Errorf("Expected an error for invalid source")
+	}
+}
+
+func TestParseGoSourceTolerant(t *testing.T) {
+	src := []byte(`package broken
+
+const Ready = true
+
+func Typing() {
+	x := os.
+}
+
+func (s *Server) Start() {
+	s.
+}
+
+type Server struct{ Addr string }
+
+func After() {}
+`)
+
+	if _, err := NewParser(GFPOptions{}).parseGoSource("broken.go", src); err == nil {
+		t.Fatalf("Expected an error without tolerant mode")
+	}
+
+	goFile, err := NewParser(GFPOptions{Tolerant: true}).parseGoSource("broken.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed in tolerant mode: %v", err)
+	}
+
+	if len(goFile.Constants) != 1 || goFile.Constants[0].Name != "Ready" {
+		t.Errorf("Constant not recovered")
+	}
+
+	if len(goFile.Types) != 1 || goFile.Types[0].Name != "Server" {
+		t.Errorf("Type not recovered")
+	}
+
+	funcNames := make(map[string]bool)
+	for _, fn := range goFile.Functions {
+		funcNames[fn.Name] = true
+	}
+	if !funcNames["Typing"] || !funcNames["After"] {
+		t.Errorf("Functions not recovered, got %v", funcNames)
+	}
+
+	if len(goFile.Diagnostics) < 2 {
+		t.Fatalf("Expected at least 2 diagnostics, got %v", goFile.Diagnostics)
+	}
+	first := goFile.Diagnostics[0]
+	if first.File != "broken.go" || first.Line != 7 || first.Column == 0 || first.Message == "" {
+		t.Errorf("Diagnostic not reported correctly: %+v", first)
+	}
+
+	if _, err := NewParser(GFPOptions{Tolerant: true}).parseGoFile(filepath.Join(t.TempDir(), "missing.go")); err == nil {
+		t.Errorf("Expected an error for a missing file in tolerant mode")
+	}
+}
+
+func TestParseGoReader(t *testing.T) {
+	goFile, err := NewParser(GFPOptions{}).parseGoReader("reader.go", strings.NewReader("package reader\n\nfunc Read() {}\n"))
+	if err != nil {
+		t.Fatalf("parseGoReader failed: %v", err)
+	}
+
+	if len(goFile.Functions) != 1 || goFile.Functions[0].Name != "Read" {
+		t.Errorf("Function not parsed correctly")
+	}
+}
+
+func TestParseGoPackageFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pkg/file1.go":     {Data: []byte("package pkg\n\nfunc Func1() {}\n")},
+		"pkg/file2.go":     {Data: []byte("package pkg\n\nfunc Func2() {}\n")},
+		"pkg/file_test.go": {Data: []byte("package pkg\n\nfunc TestFunc() {}\n")},
+		"pkg/notes.txt":    {Data: []byte("not go")},
+		"other/other.go":   {Data: []byte("package other\n")},
+		"go.mod":           {Data: []byte("module example.com/fs\n")},
+	}
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackageFS(context.Background(), fsys, "pkg")
+	if err != nil {
+		t.Fatalf("parseGoPackageFS failed: %v", err)
+	}
+
+	files := pkg.Files
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	if files[0].FileName != "pkg/file1.go" || files[1].FileName != "pkg/file2.go" {
+		t.Errorf("Unexpected file names %s, %s", files[0].FileName, files[1].FileName)
+	}
+
+	if pkg.ImportPath != "example.com/fs/pkg" {
+		t.Errorf("Expected import path 'example.com/fs/pkg', got '%s'", pkg.ImportPath)
+	}
+
+	filter := func(name string) bool { return !strings.HasSuffix(name, "2.go") }
+	pkg, err = NewParser(GFPOptions{Filter: filter}).parseGoPackageFS(context.Background(), fsys, "pkg")
+	if err != nil {
+		t.Fatalf("parseGoPackageFS with filter failed: %v", err)
+	}
+	if len(pkg.Files) != 1 || pkg.Files[0].FileName != "pkg/file1.go" {
+		t.Errorf("Expected only pkg/file1.go to pass the filter, got %d files", len(pkg.Files))
+	}
+}
+
 func TestParseImports(t *testing.T) {
 	fset := token.NewFileSet()
 	importDecl := &ast.GenDecl{
@@ -214,8 +402,470 @@ func TestParseFunction(t *testing.T) {
 		t.Errorf("Function parameters not parsed correctly")
 	}
 
-	if function.ReturnType != "string" {
-		t.Errorf("Function return type not parsed correctly, got %s", function.ReturnType)
+	if len(function.Results) != 1 || function.Results[0].Name != "" || function.Results[0].Type != "string" {
+		t.Errorf("Function results not parsed correctly, got %v", function.Results)
+	}
+}
+
+func TestParseStructFields(t *testing.T) {
+	src := []byte(`package model
+
+type User struct {
+	// ID is the primary key.
+	ID         int64  ` + "`json:\"id\" db:\"user_id\"`" + `
+	First, Last string // Names
+	*sync.Mutex
+	Base[int]
+	password   string
+}
+`)
+
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("model.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	if len(goFile.Types) != 1 {
+		t.Fatalf("Expected 1 type, got %d", len(goFile.Types))
+	}
+
+	fields := goFile.Types[0].Fields
+	if len(fields) != 6 {
+		t.Fatalf("Expected 6 fields, got %d", len(fields))
+	}
+
+	id := fields[0]
+	if id.Name != "ID" || id.Type != "int64" || id.Tag != `json:"id" db:"user_id"` || id.Doc != "ID is the primary key.\n" || !id.Exported || id.Line != 5 {
+		t.Errorf("Field ID not parsed correctly: %+v", id)
+	}
+
+	if len(id.Tags) != 2 || id.Tags[0] != (GFPTag{Key: "json", Value: "id"}) || id.Tags[1] != (GFPTag{Key: "db", Value: "user_id"}) {
+		t.Errorf("Tags of field ID not parsed correctly: %v", id.Tags)
+	}
+
+	if fields[1].Name != "First" || fields[2].Name != "Last" || fields[2].Type != "string" || fields[2].Comment != "Names\n" {
+		t.Errorf("Fields First, Last not parsed correctly: %+v %+v", fields[1], fields[2])
+	}
+
+	if fields[3].Name != "Mutex" || fields[3].Type != "*sync.Mutex" || !fields[3].Embedded || !fields[3].Exported {
+		t.Errorf("Embedded field Mutex not parsed correctly: %+v", fields[3])
+	}
+
+	if fields[4].Name != "Base" || fields[4].Type != "Base[int]" || !fields[4].Embedded {
+		t.Errorf("Embedded field Base not parsed correctly: %+v", fields[4])
+	}
+
+	if fields[5].Name != "password" || fields[5].Exported || fields[5].Embedded {
+		t.Errorf("Field password not parsed correctly: %+v", fields[5])
+	}
+}
+
+func TestParseGenerics(t *testing.T) {
+	src := []byte(`package generic
+
+type List[T any] struct {
+	items []T
+}
+
+type Pair[K comparable, V ~int | ~string] struct{}
+
+type Getter[T any] interface {
+	Get() T
+}
+
+func Map[T, U any](items []T, fn func(T) U) []U { return nil }
+
+func Sum[N ~int | ~float64 | uint8](values ...N) N { return 0 }
+
+func (l *List[T]) Push(item T) {}
+
+func (p Pair[K, V]) Key() K { var k K; return k }
+`)
+
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("generic.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	if len(goFile.Types) != 2 || len(goFile.Interfaces) != 1 || len(goFile.Functions) != 2 || len(goFile.Methods) != 2 {
+		t.Fatalf("Declarations not parsed correctly")
+	}
+
+	list := goFile.Types[0].TypeParams
+	if len(list) != 1 || !reflect.DeepEqual(list[0], GFPTypeParam{Name: "T", Constraint: "any"}) {
+		t.Errorf("Type params of List not parsed correctly: %+v", list)
+	}
+
+	pair := goFile.Types[1].TypeParams
+	if len(pair) != 2 || pair[0].Constraint != "comparable" || pair[1].Constraint != "~int | ~string" {
+		t.Errorf("Type params of Pair not parsed correctly: %+v", pair)
+	}
+	if len(pair[1].Terms) != 2 || pair[1].Terms[0] != (GFPTypeTerm{Type: "int", Tilde: true}) || pair[1].Terms[1] != (GFPTypeTerm{Type: "string", Tilde: true}) {
+		t.Errorf("Constraint terms of Pair not parsed correctly: %+v", pair[1].Terms)
+	}
+
+	if getter := goFile.Interfaces[0].TypeParams; len(getter) != 1 || getter[0].Name != "T" {
+		t.Errorf("Type params of Getter not parsed correctly: %+v", getter)
+	}
+
+	mapParams := goFile.Functions[0].TypeParams
+	if len(mapParams) != 2 || mapParams[0].Name != "T" || mapParams[1].Name != "U" || mapParams[1].Constraint != "any" {
+		t.Errorf("Type params of Map not parsed correctly: %+v", mapParams)
+	}
+
+	sum := goFile.Functions[1].TypeParams
+	if len(sum) != 1 || len(sum[0].Terms) != 3 || sum[0].Terms[2] != (GFPTypeTerm{Type: "uint8"}) {
+		t.Errorf("Type params of Sum not parsed correctly: %+v", sum)
+	}
+
+	push := goFile.Methods[0]
+	if push.Receiver != "*List[T]" || len(push.TypeParams) != 1 || push.TypeParams[0].Name != "T" {
+		t.Errorf("Receiver type params of Push not parsed correctly: %+v", push.TypeParams)
+	}
+
+	pkg, err := newPackage(".", "", []*GFPGoFile{goFile})
+	if err != nil {
+		t.Fatalf("newPackage failed: %v", err)
+	}
+	key := pkg.Methods[1].TypeParams
+	if len(key) != 2 || !reflect.DeepEqual(key[0], GFPTypeParam{Name: "K", Constraint: "comparable"}) || key[1].Constraint != "~int | ~string" {
+		t.Errorf("Receiver constraints of Key not resolved in package: %+v", key)
+	}
+}
+
+func TestParseInterfaceElements(t *testing.T) {
+	src := []byte(`package shapes
+
+import "io"
+
+type ReadWriteCloser interface {
+	io.Reader
+	io.Writer
+	Closer
+}
+
+type Number interface {
+	~int | ~int64 | float64
+}
+
+type OrderedStringer[T any] interface {
+	comparable
+	Getter[T]
+	error
+	~string
+	[]byte | string
+	String() string
+}
+`)
+
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("shapes.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	if len(goFile.Interfaces) != 3 {
+		t.Fatalf("Expected 3 interfaces, got %d", len(goFile.Interfaces))
+	}
+
+	rwc := goFile.Interfaces[0]
+	if strings.Join(rwc.Embeds, ",") != "io.Reader,io.Writer,Closer" || len(rwc.Methods) != 0 || len(rwc.TypeSet) != 0 {
+		t.Errorf("ReadWriteCloser not parsed correctly: %+v", rwc)
+	}
+
+	number := goFile.Interfaces[1]
+	if len(number.TypeSet) != 1 || len(number.Embeds) != 0 {
+		t.Fatalf("Number not parsed correctly: %+v", number)
+	}
+	expected := []GFPTypeTerm{{Type: "int", Tilde: true}, {Type: "int64", Tilde: true}, {Type: "float64"}}
+	if !reflect.DeepEqual(number.TypeSet[0].Terms, expected) || number.TypeSet[0].Line != 12 {
+		t.Errorf("Type set of Number not parsed correctly: %+v", number.TypeSet)
+	}
+
+	mixed := goFile.Interfaces[2]
+	if strings.Join(mixed.Embeds, ",") != "comparable,Getter[T],error" {
+		t.Errorf("Embeds of OrderedStringer not parsed correctly: %v", mixed.Embeds)
+	}
+	if len(mixed.TypeSet) != 2 || mixed.TypeSet[0].Terms[0] != (GFPTypeTerm{Type: "string", Tilde: true}) || mixed.TypeSet[1].Terms[0].Type != "[]byte" {
+		t.Errorf("Type set of OrderedStringer not parsed correctly: %+v", mixed.TypeSet)
+	}
+	if len(mixed.Methods) != 1 || mixed.Methods[0].Name != "String" {
+		t.Errorf("Methods of OrderedStringer not parsed correctly: %+v", mixed.Methods)
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	src := []byte(`package sig
+
+type Store interface {
+	Get(string, int) (value []byte, ok bool)
+	Put(key string, values ...[]byte) error
+}
+
+func Printf(format string, args ...any) (n int, err error) { return }
+
+func Handle(int, func(a, b string) bool) {}
+
+func (*Server) Serve(_ context.Context, _ string) {}
+`)
+
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("sig.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	get := goFile.Interfaces[0].Methods[0]
+	expectedParams := []GFPParameter{{Type: "string"}, {Type: "int"}}
+	expectedResults := []GFPParameter{{Name: "value", Type: "[]byte"}, {Name: "ok", Type: "bool"}}
+	if !reflect.DeepEqual(get.Parameters, expectedParams) || !reflect.DeepEqual(get.Results, expectedResults) {
+		t.Errorf("Signature of Get not parsed correctly: %+v %+v", get.Parameters, get.Results)
+	}
+
+	put := goFile.Interfaces[0].Methods[1]
+	expectedParams = []GFPParameter{{Name: "key", Type: "string"}, {Name: "values", Type: "[]byte", Variadic: true}}
+	if !reflect.DeepEqual(put.Parameters, expectedParams) || !reflect.DeepEqual(put.Results, []GFPParameter{{Type: "error"}}) {
+		t.Errorf("Signature of Put not parsed correctly: %+v %+v", put.Parameters, put.Results)
+	}
+
+	printf := goFile.Functions[0]
+	expectedParams = []GFPParameter{{Name: "format", Type: "string"}, {Name: "args", Type: "any", Variadic: true}}
+	expectedResults = []GFPParameter{{Name: "n", Type: "int"}, {Name: "err", Type: "error"}}
+	if !reflect.DeepEqual(printf.Parameters, expectedParams) || !reflect.DeepEqual(printf.Results, expectedResults) {
+		t.Errorf("Signature of Printf not parsed correctly: %+v %+v", printf.Parameters, printf.Results)
+	}
+
+	handle := goFile.Functions[1]
+	expectedParams = []GFPParameter{{Type: "int"}, {Type: "func(a, b string) bool"}}
+	if !reflect.DeepEqual(handle.Parameters, expectedParams) || len(handle.Results) != 0 {
+		t.Errorf("Signature of Handle not parsed correctly: %+v %+v", handle.Parameters, handle.Results)
+	}
+
+	serve := goFile.Methods[0]
+	expectedParams = []GFPParameter{{Name: "_", Type: "context.Context"}, {Name: "_", Type: "string"}}
+	if serve.Receiver != "*Server" || !reflect.DeepEqual(serve.Parameters, expectedParams) {
+		t.Errorf("Signature of Serve not parsed correctly: %+v", serve.Parameters)
+	}
+}
+
+func TestParseDeclarationDocs(t *testing.T) {
+	src := `package docs
+
+// Single is a single constant.
+const Single = 1 // one
+
+// Grouped constants.
+const (
+	// A is documented.
+	A = 1
+	B = 2 // two
+)
+
+// Lone variable group.
+var (
+	lone = "x"
+)
+
+// Point is a point.
+type Point struct{}
+
+// Grouped types.
+type (
+	// Shape is a shape.
+	Shape interface{}
+	Size  int // in bytes
+)
+
+// Reader reads.
+type Reader interface{} // trailing
+`
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("docs.go", []byte(src))
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		doc      string
+		groupDoc string
+		comment  string
+		expected [3]string
+	}{
+		{"Single", goFile.Constants[0].Doc, goFile.Constants[0].GroupDoc, goFile.Constants[0].Comment, [3]string{"Single is a single constant.\n", "", "one\n"}},
+		{"A", goFile.Constants[1].Doc, goFile.Constants[1].GroupDoc, goFile.Constants[1].Comment, [3]string{"A is documented.\n", "Grouped constants.\n", ""}},
+		{"B", goFile.Constants[2].Doc, goFile.Constants[2].GroupDoc, goFile.Constants[2].Comment, [3]string{"", "Grouped constants.\n", "two\n"}},
+		{"lone", goFile.Variables[0].Doc, goFile.Variables[0].GroupDoc, goFile.Variables[0].Comment, [3]string{"Lone variable group.\n", "Lone variable group.\n", ""}},
+		{"Point", goFile.Types[0].Doc, goFile.Types[0].GroupDoc, goFile.Types[0].Comment, [3]string{"Point is a point.\n", "", ""}},
+		{"Shape", goFile.Interfaces[0].Doc, goFile.Interfaces[0].GroupDoc, goFile.Interfaces[0].Comment, [3]string{"Shape is a shape.\n", "Grouped types.\n", ""}},
+		{"Size", goFile.Types[1].Doc, goFile.Types[1].GroupDoc, goFile.Types[1].Comment, [3]string{"", "Grouped types.\n", "in bytes\n"}},
+		{"Reader", goFile.Interfaces[1].Doc, goFile.Interfaces[1].GroupDoc, goFile.Interfaces[1].Comment, [3]string{"Reader reads.\n", "", "trailing\n"}},
+	}
+
+	for _, tt := range tests {
+		if got := [3]string{tt.doc, tt.groupDoc, tt.comment}; got != tt.expected {
+			t.Errorf("%s: expected doc, group doc and comment %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+
+	if got := goFile.Content[goFile.Types[0].DocRange.Start.Offset:goFile.Types[0].DocRange.End.Offset]; got != "// Point is a point." {
+		t.Errorf("Type doc range not correct: %q", got)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	src := `// Package notes has comments.
+package notes
+
+// A free-floating comment.
+
+// Limit is documented.
+const Limit = 1 // trailing
+
+// Shapes.
+type (
+	// Point is a point.
+	Point struct {
+		// X is documented.
+		X int // x coordinate
+		// a comment inside the struct
+
+		Y int
+	}
+	Size int
+)
+
+// Run runs.
+func (p *Point) Run() {
+	// inside the body
+}
+
+/* Another free one. */
+`
+	tests := []struct {
+		name     string
+		opts     GFPOptions
+		expected []GFPComment
+	}{
+		{
+			name: "Free",
+			opts: GFPOptions{},
+			expected: []GFPComment{
+				{Text: "// A free-floating comment.", Line: 4, Kind: "free"},
+				{Text: "/* Another free one. */", Line: 27, Kind: "free"},
+			},
+		},
+		{
+			name: "All",
+			opts: GFPOptions{AllComments: true},
+			expected: []GFPComment{
+				{Text: "// Package notes has comments.", Line: 1, Kind: "doc"},
+				{Text: "// A free-floating comment.", Line: 4, Kind: "free"},
+				{Text: "// Limit is documented.", Line: 6, Kind: "doc", Decl: "Limit"},
+				{Text: "// trailing", Line: 7, Kind: "trailing", Decl: "Limit"},
+				{Text: "// Shapes.", Line: 9, Kind: "doc"},
+				{Text: "// Point is a point.", Line: 11, Kind: "doc", Decl: "Point"},
+				{Text: "// X is documented.", Line: 13, Kind: "doc", Decl: "Point"},
+				{Text: "// x coordinate", Line: 14, Kind: "trailing", Decl: "Point"},
+				{Text: "// a comment inside the struct", Line: 15, Kind: "body", Decl: "Point"},
+				{Text: "// Run runs.", Line: 22, Kind: "doc", Decl: "Point.Run"},
+				{Text: "// inside the body", Line: 24, Kind: "body", Decl: "Point.Run"},
+				{Text: "/* Another free one. */", Line: 27, Kind: "free"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goFile, err := NewParser(tt.opts).parseGoSource("notes.go", []byte(src))
+			if err != nil {
+				t.Fatalf("parseGoSource failed: %v", err)
+			}
+			if !reflect.DeepEqual(goFile.Comments, tt.expected) {
+				t.Errorf("Unexpected comments:\ngot  %+v\nwant %+v", goFile.Comments, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	src := `package ranges
+
+import "fmt"
+
+const (
+	A = 1
+	// B is documented.
+	B = 2
+)
+
+var single = "x"
+
+// Point is a point.
+type Point struct {
+	X int // X coordinate
+}
+
+type Shape interface {
+	// Area computes the area.
+	Area() float64
+}
+
+// Print prints.
+func Print() {
+	fmt.Println(A)
+}
+`
+	goFile, err := NewParser(GFPOptions{}).parseGoSource("ranges.go", []byte(src))
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	slice := func(r GFPRange) string {
+		return goFile.Content[r.Start.Offset:r.End.Offset]
+	}
+
+	if got := slice(goFile.Imports[0].Range); got != "import \"fmt\"" {
+		t.Errorf("Import range not correct: %q", got)
+	}
+	if got := slice(goFile.Constants[1].Range); got != "B = 2" {
+		t.Errorf("Grouped constant range not correct: %q", got)
+	}
+	if got := slice(goFile.Constants[1].DocRange); got != "// B is documented." {
+		t.Errorf("Constant doc range not correct: %q", got)
+	}
+	if got := slice(goFile.Variables[0].Range); got != "var single = \"x\"" {
+		t.Errorf("Single variable range not correct: %q", got)
+	}
+	if got := slice(goFile.Types[0].Range); got != "type Point struct {\n\tX int // X coordinate\n}" {
+		t.Errorf("Type range not correct: %q", got)
+	}
+	if got := slice(goFile.Types[0].DocRange); got != "// Point is a point." {
+		t.Errorf("Type doc range not correct: %q", got)
+	}
+	if got := slice(goFile.Types[0].Fields[0].Range); got != "X int" {
+		t.Errorf("Field range not correct: %q", got)
+	}
+	if got := slice(goFile.Interfaces[0].Methods[0].Range); got != "Area() float64" {
+		t.Errorf("Interface method range not correct: %q", got)
+	}
+	if goFile.Interfaces[0].Methods[0].Doc != "Area computes the area.\n" {
+		t.Errorf("Interface method doc not correct: %q", goFile.Interfaces[0].Methods[0].Doc)
+	}
+
+	fn := goFile.Functions[0]
+	if got := slice(fn.Range); got != "func Print() {\n\tfmt.Println(A)\n}" {
+		t.Errorf("Function range not correct: %q", got)
+	}
+	if got := slice(fn.BodyRange); got != "{\n\tfmt.Println(A)\n}" {
+		t.Errorf("Function body range not correct: %q", got)
+	}
+	if fn.Range.Start.Line != 24 || fn.Range.Start.Column != 1 || fn.Range.End.Line != 26 || fn.Range.End.Column != 2 {
+		t.Errorf("Function range positions not correct: %+v", fn.Range)
+	}
+	if fn.DocRange.Start.Line != 23 {
+		t.Errorf("Function doc range not correct: %+v", fn.DocRange)
+	}
+
+	if goFile.Constants[0].DocRange != (GFPRange{}) {
+		t.Errorf("Expected a zero doc range for an undocumented constant, got %+v", goFile.Constants[0].DocRange)
 	}
 }
 
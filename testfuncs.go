@@ -0,0 +1,96 @@
+package gofileparser
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// testFileKind returns the kind of test file a file is: "internal" for a _test.go file in
+// the package under test, "external" for one in the separate package with the "_test"
+// suffix, and "" for a file that is not a test file.
+func testFileKind(name, pkgName string) string {
+	switch {
+	case !isTestFile(name):
+		return ""
+	case strings.HasSuffix(pkgName, "_test"):
+		return "external"
+	default:
+		return "internal"
+	}
+}
+
+// parseTests returns the tests, benchmarks, fuzz targets and examples declared in a test
+// file, in source order. Functions are recognized the way the go test tool does: by their
+// name prefix, which must not be followed by a lower-case letter, and their signature.
+// Examples include the text of their "// Output:" comment, as extracted by go/doc.
+func parseTests(fset *token.FileSet, file *ast.File) []GFPTest {
+	examples := make(map[string]*doc.Example)
+	for _, example := range doc.Examples(file) {
+		examples["Example"+example.Name] = example
+	}
+
+	var tests []GFPTest
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.TypeParams != nil {
+			continue
+		}
+
+		test := GFPTest{
+			Name:  fn.Name.Name,
+			Line:  fset.Position(fn.Name.Pos()).Line,
+			Range: nodeRange(fset, fn),
+		}
+		switch {
+		case isTestFunc(fn, "Test", "T"):
+			test.Kind = "test"
+		case isTestFunc(fn, "Benchmark", "B"):
+			test.Kind = "benchmark"
+		case isTestFunc(fn, "Fuzz", "F"):
+			test.Kind = "fuzz"
+		default:
+			example, ok := examples[fn.Name.Name]
+			if !ok {
+				continue
+			}
+			test.Kind = "example"
+			test.Output = example.Output
+			test.HasOutput = example.Output != "" || example.EmptyOutput
+			test.Unordered = example.Unordered
+		}
+		tests = append(tests, test)
+	}
+	return tests
+}
+
+// isTestFunc reports whether fn is named like a test function with the given prefix and
+// takes a single *testing.<param> argument, e.g. "func TestFoo(t *testing.T)".
+func isTestFunc(fn *ast.FuncDecl, prefix, param string) bool {
+	if !hasTestPrefix(fn.Name.Name, prefix) || fn.Type.Results.NumFields() != 0 || fn.Type.Params.NumFields() != 1 {
+		return false
+	}
+	ptr, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := ptr.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == param
+}
+
+// hasTestPrefix reports whether name starts with prefix and the rest of the name, if any,
+// does not start with a lower-case letter ("TestFoo" and "Test_foo", but not "Testify").
+func hasTestPrefix(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLower(r)
+}
@@ -0,0 +1,203 @@
+package gofileparser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testFuncsSource = `package shapes
+
+import (
+	"fmt"
+	tst "testing"
+)
+
+func TestArea(t *tst.T) {}
+
+func Test_perimeter(t *tst.T) {}
+
+func Testify(t *tst.T) {}
+
+func TestHelper(t *tst.T) int { return 0 }
+
+func BenchmarkArea(b *tst.B) {}
+
+func FuzzParse(f *tst.F) {}
+
+func FuzzWrongParam(t *tst.T) {}
+
+func Example() {
+	fmt.Println("hello")
+	// Output: hello
+}
+
+func ExampleArea() {
+	fmt.Println(1)
+	fmt.Println(2)
+	// Unordered output:
+	// 2
+	// 1
+}
+
+func ExampleArea_empty() {
+	// Output:
+}
+
+func ExampleArea_unchecked() {
+	fmt.Println("not run")
+}
+
+func ExampleArea_params(n int) {}
+
+func (s shape) TestMethod(t *tst.T) {}
+`
+
+func TestParseTests(t *testing.T) {
+	goFile := mustParseSource(t, "shapes_test.go", testFuncsSource)
+
+	if goFile.TestKind != "internal" {
+		t.Errorf("Expected test kind internal, got %q", goFile.TestKind)
+	}
+
+	type test struct {
+		name      string
+		kind      string
+		output    string
+		hasOutput bool
+		unordered bool
+	}
+	expected := []test{
+		{"TestArea", "test", "", false, false},
+		{"Test_perimeter", "test", "", false, false},
+		{"BenchmarkArea", "benchmark", "", false, false},
+		{"FuzzParse", "fuzz", "", false, false},
+		{"Example", "example", "hello\n", true, false},
+		{"ExampleArea", "example", "2\n1\n", true, true},
+		{"ExampleArea_empty", "example", "", true, false},
+		{"ExampleArea_unchecked", "example", "", false, false},
+	}
+	var got []test
+	for _, tt := range goFile.Tests {
+		got = append(got, test{tt.Name, tt.Kind, tt.Output, tt.HasOutput, tt.Unordered})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected tests:\ngot  %+v\nwant %+v", got, expected)
+	}
+	if len(goFile.Tests) > 0 && goFile.Tests[0].Line != 8 {
+		t.Errorf("Expected TestArea on line 8, got %d", goFile.Tests[0].Line)
+	}
+}
+
+func TestTestFileKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"a.go", "package a\n\nfunc TestA(t *testing.T) {}\n", ""},
+		{"a_test.go", "package a\n", "internal"},
+		{"a_test.go", "package a_test\n", "external"},
+	}
+
+	for _, tt := range tests {
+		goFile := mustParseSource(t, tt.name, tt.src)
+		if goFile.TestKind != tt.expected {
+			t.Errorf("%s (%q): expected test kind %q, got %q", tt.name, tt.src, tt.expected, goFile.TestKind)
+		}
+		if tt.expected == "" && len(goFile.Tests) != 0 {
+			t.Errorf("%s: expected no tests outside test files, got %v", tt.name, goFile.Tests)
+		}
+	}
+}
+
+func TestParseGoPackageIncludeTests(t *testing.T) {
+	root := t.TempDir()
+	createTempGoFile(t, root, "go.mod", "module example.com/mod\n")
+	dir := filepath.Join(root, "shapes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	createTempGoFile(t, dir, "shapes.go", "package shapes\n\nfunc Area() int { return 1 }\n")
+	createTempGoFile(t, dir, "shapes_test.go", "package shapes\n\nimport \"testing\"\n\nfunc helper() {}\n\nfunc TestArea(t *testing.T) {}\n")
+	createTempGoFile(t, dir, "example_test.go", "package shapes_test\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/mod/shapes\"\n)\n\nfunc ExampleArea() {\n\tfmt.Println(shapes.Area())\n\t// Output: 1\n}\n")
+
+	pkg, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+	if len(pkg.Files) != 1 || len(pkg.Tests) != 0 {
+		t.Errorf("Expected test files to be skipped by default, got %d files and %v", len(pkg.Files), pkg.Tests)
+	}
+
+	for _, typeCheck := range []bool{false, true} {
+		pkg, err := NewParser(GFPOptions{IncludeTests: true, TypeCheck: typeCheck}).parseGoPackage(context.Background(), dir)
+		if err != nil {
+			t.Fatalf("parseGoPackage with tests failed: %v", err)
+		}
+
+		if pkg.Name != "shapes" || len(pkg.Files) != 3 {
+			t.Errorf("Expected package shapes with 3 files, got %s with %d", pkg.Name, len(pkg.Files))
+		}
+		var names []string
+		for _, fn := range pkg.Functions {
+			names = append(names, fn.Name)
+		}
+		if expected := []string{"Area", "helper", "TestArea"}; !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected functions %v, got %v", expected, names)
+		}
+		names = nil
+		for _, test := range pkg.Tests {
+			names = append(names, test.Name)
+		}
+		if expected := []string{"ExampleArea", "TestArea"}; !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected tests %v, got %v", expected, names)
+		}
+		for _, file := range pkg.Files {
+			if len(file.Diagnostics) != 0 {
+				t.Errorf("Type check %v: unexpected diagnostics in %s: %v", typeCheck, file.FileName, file.Diagnostics)
+			}
+		}
+	}
+}
+
+func TestParseGoPackageOnlyTests(t *testing.T) {
+	dir := t.TempDir()
+	createTempGoFile(t, dir, "a_test.go", "package a_test\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n")
+
+	if _, err := NewParser(GFPOptions{}).parseGoPackage(context.Background(), dir); !errors.Is(err, ErrNoGoFiles) {
+		t.Errorf("Expected ErrNoGoFiles, got %v", err)
+	}
+
+	pkg, err := NewParser(GFPOptions{IncludeTests: true}).parseGoPackage(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("parseGoPackage with tests failed: %v", err)
+	}
+	if pkg.Name != "a_test" || len(pkg.Functions) != 1 || len(pkg.Tests) != 1 {
+		t.Errorf("Expected package a_test with TestA, got %+v", pkg)
+	}
+}
+
+func TestHasTestPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"Test", true},
+		{"TestFoo", true},
+		{"Test_foo", true},
+		{"Test2", true},
+		{"Testify", false},
+		{"Tes", false},
+		{"test", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasTestPrefix(tt.name, "Test"); got != tt.expected {
+			t.Errorf("hasTestPrefix(%q) = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package gofileparser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// goModule describes a module whose packages can be imported during type checking.
+type goModule struct {
+	fsys fs.FS  // File system rooted at the module directory
+	path string // Module path
+}
+
+// findDiskModule returns the module enclosing a directory on disk, or nil if there is none.
+func findDiskModule(dir string) *goModule {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	root, modulePath, ok := findModule(filepath.ToSlash(absDir), func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.FromSlash(name))
+	})
+	if !ok {
+		return nil
+	}
+	return &goModule{fsys: os.DirFS(filepath.FromSlash(root)), path: modulePath}
+}
+
+// localImporter is a types.ImporterFrom that never touches the network. Standard library
+// packages are type-checked from GOROOT sources, packages of the module from its
+// directory; every other import fails.
+type localImporter struct {
+	fset     *token.FileSet
+	module   *goModule
+	build    build.Context // Build context module package files are matched against, reading from module.fsys
+	std      types.ImporterFrom
+	packages map[string]*types.Package
+	loading  map[string]bool
+}
+
+// newLocalImporter returns a localImporter that parses into fset. The module may be nil.
+// Files of module packages are selected with buildCtx; standard library packages are
+// always selected with build.Default.
+func newLocalImporter(fset *token.FileSet, module *goModule, buildCtx build.Context) *localImporter {
+	if module != nil {
+		buildCtx = fsBuildContext(buildCtx, module.fsys)
+	}
+	return &localImporter{
+		fset:     fset,
+		module:   module,
+		build:    buildCtx,
+		std:      importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		packages: make(map[string]*types.Package),
+		loading:  make(map[string]bool),
+	}
+}
+
+// Import implements types.Importer.
+func (imp *localImporter) Import(importPath string) (*types.Package, error) {
+	return imp.ImportFrom(importPath, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom.
+func (imp *localImporter) ImportFrom(importPath, dir string, mode types.ImportMode) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if imp.module != nil && (importPath == imp.module.path || strings.HasPrefix(importPath, imp.module.path+"/")) {
+		return imp.importModulePackage(importPath)
+	}
+	if isStandardImportPath(importPath) {
+		return imp.std.ImportFrom(importPath, "", mode)
+	}
+	return nil, fmt.Errorf("package %s is neither in the standard library nor in the module", importPath)
+}
+
+// importModulePackage parses and type-checks a package of the module.
+func (imp *localImporter) importModulePackage(importPath string) (*types.Package, error) {
+	if pkg, ok := imp.packages[importPath]; ok {
+		return pkg, nil
+	}
+	if imp.loading[importPath] {
+		return nil, fmt.Errorf("import cycle through %s", importPath)
+	}
+	imp.loading[importPath] = true
+	defer delete(imp.loading, importPath)
+
+	dir := strings.TrimPrefix(strings.TrimPrefix(importPath, imp.module.path), "/")
+	if dir == "" {
+		dir = "."
+	}
+	names, err := fs.Glob(imp.module.fsys, path.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, name := range names {
+		if isTestFile(name) {
+			continue
+		}
+		if match, err := matchBuildContext(&imp.build, name); err != nil || !match {
+			continue
+		}
+		content, err := fs.ReadFile(imp.module.fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		file, _ := parser.ParseFile(imp.fset, name, content, parser.AllErrors)
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files for package %s", importPath)
+	}
+
+	pkg, _ := imp.check(importPath, files)
+	imp.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// check type-checks files as the package with the given import path and returns the
+// resulting package along with any type errors as diagnostics.
+func (imp *localImporter) check(importPath string, files []*ast.File) (*types.Package, []GFPDiagnostic) {
+	var diagnostics []GFPDiagnostic
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok {
+				pos := terr.Fset.Position(terr.Pos)
+				diagnostics = append(diagnostics, GFPDiagnostic{
+					File:    pos.Filename,
+					Line:    pos.Line,
+					Column:  pos.Column,
+					Message: terr.Msg,
+				})
+			}
+		},
+	}
+	pkg, _ := conf.Check(importPath, imp.fset, files, nil)
+	return pkg, diagnostics
+}
+
+// typeCheckPackage type-checks the ASTs of a package and annotates the matching GFP_GoFile
+// structures with resolved type information. Type errors are added to the diagnostics of
+// the file they occur in. Files of the external test package are checked separately, as
+// the package "<importPath>_test" importing the package under test.
+func typeCheckPackage(imp *localImporter, importPath string, files []*ast.File, goFiles []*GFPGoFile) {
+	var pkgFiles, testFiles []*ast.File
+	var pkgGoFiles, testGoFiles []*GFPGoFile
+	for i, goFile := range goFiles {
+		if goFile.TestKind == "external" {
+			testFiles = append(testFiles, files[i])
+			testGoFiles = append(testGoFiles, goFile)
+		} else {
+			pkgFiles = append(pkgFiles, files[i])
+			pkgGoFiles = append(pkgGoFiles, goFile)
+		}
+	}
+
+	if len(pkgFiles) > 0 {
+		typeCheckFiles(imp, importPath, pkgFiles, pkgGoFiles)
+	}
+	if len(testFiles) > 0 {
+		testPath := importPath
+		if testPath != "" {
+			testPath += "_test"
+		}
+		typeCheckFiles(imp, testPath, testFiles, testGoFiles)
+	}
+}
+
+// typeCheckFiles type-checks the ASTs of a single package and annotates goFiles.
+func typeCheckFiles(imp *localImporter, importPath string, files []*ast.File, goFiles []*GFPGoFile) {
+	pkg, diagnostics := imp.check(importPath, files)
+
+	for _, diagnostic := range diagnostics {
+		target := goFiles[0]
+		for _, goFile := range goFiles {
+			if goFile.FileName == diagnostic.File {
+				target = goFile
+				break
+			}
+		}
+		target.Diagnostics = append(target.Diagnostics, diagnostic)
+	}
+
+	if pkg != nil {
+		annotateTypes(pkg.Scope(), goFiles)
+		annotateConstants(pkg.Scope(), goFiles)
+	}
+}
+
+// annotateTypes sets GFP_TypeInfo on the parameters, results and struct fields of the
+// declarations in goFiles, looking the declarations up by name in the package scope.
+func annotateTypes(scope *types.Scope, goFiles []*GFPGoFile) {
+	for _, goFile := range goFiles {
+		for i := range goFile.Functions {
+			fn := &goFile.Functions[i]
+			if obj, ok := scope.Lookup(fn.Name).(*types.Func); ok {
+				annotateSignature(obj.Type().(*types.Signature), fn.Parameters, fn.Results)
+			}
+		}
+
+		for i := range goFile.Methods {
+			method := &goFile.Methods[i]
+			named, ok := lookupType(scope, receiverTypeName(method.Receiver)).(*types.Named)
+			if !ok {
+				continue
+			}
+			for j := 0; j < named.NumMethods(); j++ {
+				if fn := named.Method(j); fn.Name() == method.Name {
+					annotateSignature(fn.Type().(*types.Signature), method.Parameters, method.Results)
+				}
+			}
+		}
+
+		for i := range goFile.Types {
+			typ := &goFile.Types[i]
+			st, ok := underlying(lookupType(scope, typ.Name)).(*types.Struct)
+			if !ok || st.NumFields() != len(typ.Fields) {
+				continue
+			}
+			for j := range typ.Fields {
+				typ.Fields[j].TypeInfo = newTypeInfo(st.Field(j).Type())
+			}
+		}
+
+		for i := range goFile.Interfaces {
+			iface, ok := underlying(lookupType(scope, goFile.Interfaces[i].Name)).(*types.Interface)
+			if !ok {
+				continue
+			}
+			methods := goFile.Interfaces[i].Methods
+			for j := range methods {
+				for k := 0; k < iface.NumExplicitMethods(); k++ {
+					if fn := iface.ExplicitMethod(k); fn.Name() == methods[j].Name {
+						annotateSignature(fn.Type().(*types.Signature), methods[j].Parameters, methods[j].Results)
+					}
+				}
+			}
+		}
+	}
+}
+
+// annotateSignature sets GFP_TypeInfo on parameters and results from a signature.
+// Nothing is annotated if the number of parameters or results does not match.
+func annotateSignature(sig *types.Signature, params, results []GFPParameter) {
+	annotateTuple(sig.Params(), params)
+	annotateTuple(sig.Results(), results)
+}
+
+// annotateTuple sets GFP_TypeInfo on params from the variables of a tuple.
+func annotateTuple(tuple *types.Tuple, params []GFPParameter) {
+	if tuple.Len() != len(params) {
+		return
+	}
+	for i := range params {
+		t := tuple.At(i).Type()
+		if slice, ok := t.(*types.Slice); ok && params[i].Variadic {
+			t = slice.Elem()
+		}
+		params[i].TypeInfo = newTypeInfo(t)
+	}
+}
+
+// lookupType returns the type declared under name in scope, or nil.
+func lookupType(scope *types.Scope, name string) types.Type {
+	if obj, ok := scope.Lookup(name).(*types.TypeName); ok {
+		return obj.Type()
+	}
+	return nil
+}
+
+// underlying returns the underlying type of t, or nil if t is nil.
+func underlying(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	return t.Underlying()
+}
+
+// newTypeInfo describes a resolved type, or returns nil for invalid types.
+func newTypeInfo(t types.Type) *GFPTypeInfo {
+	if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.Invalid {
+		return nil
+	}
+
+	info := &GFPTypeInfo{
+		Type: types.TypeString(t, nil),
+		Kind: typeKind(t),
+	}
+
+	base := t
+	for {
+		pointer, ok := base.(*types.Pointer)
+		if !ok {
+			break
+		}
+		base = pointer.Elem()
+	}
+	if _, ok := base.(*types.TypeParam); !ok {
+		if named, ok := base.(interface{ Obj() *types.TypeName }); ok && named.Obj().Pkg() != nil {
+			info.Package = named.Obj().Pkg().Path()
+		}
+	}
+
+	return info
+}
+
+// typeKind returns the kind of the underlying type of t.
+func typeKind(t types.Type) string {
+	if _, ok := t.(*types.TypeParam); ok {
+		return "typeparam"
+	}
+	switch t.Underlying().(type) {
+	case *types.Basic:
+		return "basic"
+	case *types.Pointer:
+		return "pointer"
+	case *types.Slice:
+		return "slice"
+	case *types.Array:
+		return "array"
+	case *types.Map:
+		return "map"
+	case *types.Chan:
+		return "chan"
+	case *types.Signature:
+		return "func"
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		return "interface"
+	}
+	return "invalid"
+}
+
+// importedInterface type-checks the package with the given import path and returns the
+// interface it declares under name.
+func importedInterface(imp *localImporter, importPath, name string) (*types.Interface, error) {
+	pkg, err := imp.Import(importPath)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a type of %s", name, importPath)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s is not an interface", pkg.Name(), name)
+	}
+	return iface, nil
+}
+
+// interfaceMethods describes the complete method set of a type-checked interface. Types
+// are written with qualify and annotated with GFP_TypeInfo.
+func interfaceMethods(iface *types.Interface, qualify types.Qualifier) []GFPInterfaceMethod {
+	var methods []GFPInterfaceMethod
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		sig := fn.Type().(*types.Signature)
+		method := GFPInterfaceMethod{Name: fn.Name()}
+		for j := 0; j < sig.Params().Len(); j++ {
+			t := sig.Params().At(j).Type()
+			p := GFPParameter{Name: sig.Params().At(j).Name()}
+			if sig.Variadic() && j == sig.Params().Len()-1 {
+				t = t.(*types.Slice).Elem()
+				p.Variadic = true
+			}
+			p.Type = types.TypeString(t, qualify)
+			p.TypeInfo = newTypeInfo(t)
+			method.Parameters = append(method.Parameters, p)
+		}
+		for j := 0; j < sig.Results().Len(); j++ {
+			t := sig.Results().At(j).Type()
+			method.Results = append(method.Results, GFPParameter{
+				Name:     sig.Results().At(j).Name(),
+				Type:     types.TypeString(t, qualify),
+				TypeInfo: newTypeInfo(t),
+			})
+		}
+		methods = append(methods, method)
+	}
+	return methods
+}
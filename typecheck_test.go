@@ -0,0 +1,160 @@
+package gofileparser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createTypeCheckModule writes a small module with a library and an application package.
+func createTypeCheckModule(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"lib", "app"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+	}
+	createTempGoFile(t, root, "go.mod", "module example.com/tc\n\ngo 1.21\n")
+	createTempGoFile(t, filepath.Join(root, "lib"), "lib.go", `package lib
+
+type Request struct{ ID int }
+
+type Handler interface {
+	Handle(req *Request) error
+}
+`)
+	createTempGoFile(t, filepath.Join(root, "app"), "app.go", `package app
+
+import (
+	l "example.com/tc/lib"
+	str "strings"
+	. "time"
+)
+
+type Server struct {
+	Handler l.Handler
+	*str.Builder
+	Timeout Duration
+	items   []l.Request
+}
+
+type Runner interface {
+	Run(Duration) *l.Request
+}
+
+func Serve[T any](h l.Handler, opts ...T) (n int, err error) { return 0, nil }
+
+func (s *Server) Run(d Duration) *l.Request { return nil }
+
+var broken = missing
+`)
+	return root
+}
+
+func TestParseGoPackageTypeCheck(t *testing.T) {
+	root := createTypeCheckModule(t)
+
+	pkg, err := NewParser(GFPOptions{TypeCheck: true}).parseGoPackage(context.Background(), filepath.Join(root, "app"))
+	if err != nil {
+		t.Fatalf("parseGoPackage failed: %v", err)
+	}
+
+	fields := pkg.Types[0].Fields
+	expected := []GFPTypeInfo{
+		{Type: "example.com/tc/lib.Handler", Kind: "interface", Package: "example.com/tc/lib"},
+		{Type: "*strings.Builder", Kind: "pointer", Package: "strings"},
+		{Type: "time.Duration", Kind: "basic", Package: "time"},
+		{Type: "[]example.com/tc/lib.Request", Kind: "slice"},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d", len(expected), len(fields))
+	}
+	for i, field := range fields {
+		if field.TypeInfo == nil || *field.TypeInfo != expected[i] {
+			t.Errorf("Type info of field %s not correct: %+v", field.Name, field.TypeInfo)
+		}
+	}
+
+	serve := pkg.Functions[0]
+	if info := serve.Parameters[0].TypeInfo; info == nil || info.Type != "example.com/tc/lib.Handler" {
+		t.Errorf("Type info of parameter h not correct: %+v", info)
+	}
+	if info := serve.Parameters[1].TypeInfo; info == nil || info.Type != "T" || info.Kind != "typeparam" || info.Package != "" {
+		t.Errorf("Type info of variadic parameter opts not correct: %+v", info)
+	}
+	if info := serve.Results[1].TypeInfo; info == nil || info.Type != "error" || info.Kind != "interface" {
+		t.Errorf("Type info of result err not correct: %+v", info)
+	}
+
+	run := pkg.Methods[0]
+	if info := run.Parameters[0].TypeInfo; info == nil || info.Type != "time.Duration" {
+		t.Errorf("Type info of dot-imported parameter not correct: %+v", info)
+	}
+	if info := run.Results[0].TypeInfo; info == nil || info.Type != "*example.com/tc/lib.Request" || info.Package != "example.com/tc/lib" {
+		t.Errorf("Type info of method result not correct: %+v", info)
+	}
+
+	ifaceRun := pkg.Interfaces[0].Methods[0]
+	if info := ifaceRun.Parameters[0].TypeInfo; info == nil || info.Type != "time.Duration" {
+		t.Errorf("Type info of interface method parameter not correct: %+v", info)
+	}
+
+	diagnostics := pkg.Files[0].Diagnostics
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, "missing") || diagnostics[0].Line != 24 {
+		t.Errorf("Expected a type error for the undefined name, got %+v", diagnostics)
+	}
+}
+
+func TestParseGoModuleTypeCheck(t *testing.T) {
+	root := createTypeCheckModule(t)
+
+	module, err := NewParser(GFPOptions{TypeCheck: true}).parseGoModule(context.Background(), root, root)
+	if err != nil {
+		t.Fatalf("parseGoModule failed: %v", err)
+	}
+
+	lib := module.Packages["example.com/tc/lib"]
+	if lib == nil {
+		t.Fatalf("Package lib not found")
+	}
+	handle := lib.Interfaces[0].Methods[0]
+	if info := handle.Parameters[0].TypeInfo; info == nil || info.Type != "*example.com/tc/lib.Request" {
+		t.Errorf("Type info of Handle parameter not correct: %+v", info)
+	}
+
+	app := module.Packages["example.com/tc/app"]
+	if app == nil || app.Types[0].Fields[0].TypeInfo == nil {
+		t.Errorf("Package app not type-checked")
+	}
+}
+
+func TestParseGoSourceTypeCheck(t *testing.T) {
+	src := []byte("package single\n\nimport \"example.com/unknown\"\n\nfunc Count(s []string, u unknown.Thing) int { return len(s) }\n")
+
+	goFile, err := NewParser(GFPOptions{TypeCheck: true}).parseGoSource("single.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+
+	params := goFile.Functions[0].Parameters
+	if info := params[0].TypeInfo; info == nil || info.Type != "[]string" || info.Kind != "slice" {
+		t.Errorf("Type info of parameter s not correct: %+v", info)
+	}
+	if params[1].TypeInfo != nil {
+		t.Errorf("Expected no type info for an unresolved type, got %+v", params[1].TypeInfo)
+	}
+	if len(goFile.Diagnostics) == 0 {
+		t.Errorf("Expected a diagnostic for the unresolved import")
+	}
+
+	goFile, err = NewParser(GFPOptions{}).parseGoSource("single.go", src)
+	if err != nil {
+		t.Fatalf("parseGoSource failed: %v", err)
+	}
+	if goFile.Functions[0].Parameters[0].TypeInfo != nil || len(goFile.Diagnostics) != 0 {
+		t.Errorf("Expected no type information without type-checked mode")
+	}
+}
@@ -1,98 +1,385 @@
 package gofileparser
 
+import "sync"
+
 // GFPGoFile represents the structure of a parsed Go file.
 type GFPGoFile struct {
-	Package    string          // Name of the package
-	Imports    []GFPImport    // List of imports
-	Constants  []GFPConstant  // List of constants
-	Variables  []GFPVariable  // List of variables
-	Types      []GFPType      // List of type definitions
-	Functions  []GFPFunction  // List of functions
-	Methods    []GFPMethod    // List of methods
-	Interfaces []GFPInterface // List of interfaces
-	Comments   []GFPComment   // List of comments not associated with declarations
-	FileDoc    string          // File-level documentation comment
-	Content    string          // Entire file content
+	FileName        string          `json:"fileName"`                  // Name of the file as given to the parser (path or in-memory name)
+	Package         string          `json:"package"`                   // Name of the package
+	Imports         []GFPImport     `json:"imports,omitempty"`         // List of imports
+	Constants       []GFPConstant   `json:"constants,omitempty"`       // List of constants
+	Variables       []GFPVariable   `json:"variables,omitempty"`       // List of variables
+	Types           []GFPType       `json:"types,omitempty"`           // List of type definitions
+	Functions       []GFPFunction   `json:"functions,omitempty"`       // List of functions
+	Methods         []GFPMethod     `json:"methods,omitempty"`         // List of methods
+	Interfaces      []GFPInterface  `json:"interfaces,omitempty"`      // List of interfaces
+	Comments        []GFPComment    `json:"comments,omitempty"`        // Free-floating comments, or all comments if GFPOptions.AllComments is set
+	FileDoc         string          `json:"fileDoc,omitempty"`         // File-level documentation comment
+	FileDocComment  *GFPDocComment  `json:"fileDocComment,omitempty"`  // File-level documentation comment parsed into blocks
+	Directives      []GFPDirective  `json:"directives,omitempty"`      // Compiler directives and tool markers not attached to a declaration, e.g. a file-level //go:generate
+	BuildConstraint string          `json:"buildConstraint,omitempty"` // Expression of the //go:build line (or combined // +build lines), e.g. "linux && amd64"; file name suffixes are not included
+	TestKind        string          `json:"testKind,omitempty"`        // Kind of test file: "internal" (package foo), "external" (package foo_test) or empty for non-test files
+	Tests           []GFPTest       `json:"tests,omitempty"`           // Tests, benchmarks, fuzz targets and examples (only for test files)
+	Content         string          `json:"content,omitempty"`         // Entire file content
+	Diagnostics     []GFPDiagnostic `json:"diagnostics,omitempty"`     // Syntax errors recovered from in tolerant mode
+}
+
+// GFPModule represents a parsed Go module.
+type GFPModule struct {
+	Path      string                 `json:"path"`                // Module path from the go.mod module directive
+	GoVersion string                 `json:"goVersion,omitempty"` // Go version from the go.mod go directive
+	Dir       string                 `json:"dir"`                 // Root directory of the module
+	Packages  map[string]*GFPPackage `json:"packages,omitempty"`  // Packages in the module, keyed by import path
+}
+
+// GFPPackage represents a parsed Go package.
+type GFPPackage struct {
+	Name       string         `json:"name"`                 // Name of the package
+	ImportPath string         `json:"importPath,omitempty"` // Import path of the package (empty if no enclosing go.mod was found)
+	Dir        string         `json:"dir"`                  // Directory containing the package sources
+	Doc        string         `json:"doc,omitempty"`        // Package documentation, taken from doc.go or the first file that has it
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	Files      []*GFPGoFile   `json:"files,omitempty"`      // Parsed files of the package
+	Imports    []GFPImport    `json:"imports,omitempty"`    // Imports of all files, without duplicates
+	Constants  []GFPConstant  `json:"constants,omitempty"`  // Constants of all files
+	Variables  []GFPVariable  `json:"variables,omitempty"`  // Variables of all files
+	Types      []GFPType      `json:"types,omitempty"`      // Type definitions of all files, with their methods attached
+	Functions  []GFPFunction  `json:"functions,omitempty"`  // Functions of all files
+	Methods    []GFPMethod    `json:"methods,omitempty"`    // Methods of all files
+	Interfaces []GFPInterface `json:"interfaces,omitempty"` // Interfaces of all files
+	Tests      []GFPTest      `json:"tests,omitempty"`      // Tests of all test files, including those of the external test package
+}
+
+// GFPTest represents a test, benchmark, fuzz target or example function of a test file.
+type GFPTest struct {
+	Name      string   `json:"name"`                // Name of the function (e.g. "TestParse" or "ExampleParser_Parse")
+	Kind      string   `json:"kind"`                // Kind of the function: test, benchmark, fuzz or example
+	Output    string   `json:"output,omitempty"`    // Expected output of an example, from its "// Output:" comment
+	HasOutput bool     `json:"hasOutput,omitempty"` // Whether an example has an output comment; examples without one are compiled but not run
+	Unordered bool     `json:"unordered,omitempty"` // Whether an example's output is checked in any order ("// Unordered output:")
+	Line      int      `json:"line"`                // Line number where the function is declared
+	Range     GFPRange `json:"range"`               // Source range of the declaration
+}
+
+// GFPDirective represents a compiler directive or tool marker comment, such as
+// "//go:generate stringer -type=Kind", "//nolint:errcheck" or "// +kubebuilder:object:root=true".
+// Directives are attached to the declaration whose doc comment, group doc comment or
+// trailing line comment they appear in, to the declaration just below a group of markers,
+// and to the function whose body they appear in; the others belong to the file.
+type GFPDirective struct {
+	Name string   `json:"name"`           // Name of the directive: "go:generate", "nolint", "export", "+kubebuilder:object:root", ...
+	Args []string `json:"args,omitempty"` // Arguments: unquoted words for "//tool:name" directives, linters for nolint, values or "key=value" pairs for markers
+	Text string   `json:"text"`           // Full text of the comment, including the slashes
+	Line int      `json:"line"`           // Line number of the comment
+}
+
+// GFPDocComment represents a documentation comment parsed with go/doc/comment.
+type GFPDocComment struct {
+	Synopsis   string        `json:"synopsis,omitempty"`   // First sentence of the comment, as shown in package lists
+	Deprecated string        `json:"deprecated,omitempty"` // Text of the "Deprecated:" paragraph without its prefix, if any
+	Blocks     []GFPDocBlock `json:"blocks,omitempty"`     // Headings, paragraphs, lists and code blocks, in order
+	Links      []GFPDocLink  `json:"links,omitempty"`      // Doc links ("[pkg.Symbol]") of all blocks, in order
+}
+
+// GFPDocBlock represents a block of a documentation comment.
+type GFPDocBlock struct {
+	Kind  string           `json:"kind"`            // Kind of the block: heading, paragraph, list or code
+	Text  string           `json:"text,omitempty"`  // Plain text of a heading or paragraph, or the preformatted text of a code block
+	Spans []GFPDocSpan     `json:"spans,omitempty"` // Inline elements of a heading or paragraph
+	Items []GFPDocListItem `json:"items,omitempty"` // Items of a list
+}
+
+// GFPDocListItem represents an item of a list in a documentation comment.
+type GFPDocListItem struct {
+	Number string        `json:"number,omitempty"` // Number of the item in a numbered list (e.g. "1"), empty for bullets
+	Blocks []GFPDocBlock `json:"blocks,omitempty"` // Content of the item
+}
+
+// GFPDocSpan represents an inline element of a heading or paragraph.
+type GFPDocSpan struct {
+	Kind    string      `json:"kind"`              // Kind of the element: text, italic, link or docLink
+	Text    string      `json:"text"`              // Plain text of the element
+	URL     string      `json:"url,omitempty"`     // Target of a link
+	DocLink *GFPDocLink `json:"docLink,omitempty"` // Target of a doc link
+}
+
+// GFPDocLink represents a doc link such as "[io.Reader]" or "[Parser.Parse]".
+type GFPDocLink struct {
+	Text       string `json:"text"`                 // Text of the link
+	ImportPath string `json:"importPath,omitempty"` // Import path of the linked package, empty for the current package
+	Recv       string `json:"recv,omitempty"`       // Receiver type of a linked method
+	Name       string `json:"name,omitempty"`       // Name of the linked symbol, empty for links to a package
+}
+
+// GFPEnum represents a defined type used as an enum: a type based on a predeclared basic
+// type, such as "type Color int", together with the constants of that type.
+type GFPEnum struct {
+	Name       string        `json:"name"`                // Name of the enum type
+	Package    string        `json:"package,omitempty"`   // Import path of the package declaring the type
+	Underlying string        `json:"underlying"`          // Predeclared type the enum is based on (e.g. "int" or "string")
+	Members    []GFPConstant `json:"members,omitempty"`   // Constants of the type in declaration order, with their evaluated values
+	HasString  bool          `json:"hasString,omitempty"` // Whether the type already declares a String() string method
+	Line       int           `json:"line"`                // Line number where the type is declared
+}
+
+// GFPImplementation represents the result of checking a concrete type against an interface.
+type GFPImplementation struct {
+	Interface        string                 `json:"interface"`                  // Name of the interface
+	InterfacePackage string                 `json:"interfacePackage,omitempty"` // Import path of the package declaring the interface
+	Type             string                 `json:"type"`                       // Name of the concrete type
+	TypePackage      string                 `json:"typePackage,omitempty"`      // Import path of the package declaring the type
+	ByValue          bool                   `json:"byValue,omitempty"`          // Whether values of the type implement the interface
+	ByPointer        bool                   `json:"byPointer,omitempty"`        // Whether pointers to the type implement the interface
+	PointerMethods   []string               `json:"pointerMethods,omitempty"`   // Matching methods declared with pointer receivers
+	Missing          []string               `json:"missing,omitempty"`          // Interface methods the type does not declare
+	Mismatched       []GFPSignatureMismatch `json:"mismatched,omitempty"`       // Methods declared with a different signature
+	UnresolvedEmbeds []string               `json:"unresolvedEmbeds,omitempty"` // Embedded interfaces whose methods could not be checked
+}
+
+// GFPSignatureMismatch represents a method whose signature differs from the interface's.
+type GFPSignatureMismatch struct {
+	Method   string `json:"method"`   // Name of the method
+	Expected string `json:"expected"` // Signature required by the interface, e.g. "(string) error"
+	Actual   string `json:"actual"`   // Signature declared on the type
 }
 
 // GFPImport represents a single import statement.
 type GFPImport struct {
-	Path string // Import path (e.g., "fmt")
-	Name string // Local name (alias) for the import, if any
-	Line int    // Line number where the import is declared
+	Path  string   `json:"path"`           // Import path (e.g., "fmt")
+	Name  string   `json:"name,omitempty"` // Local name (alias) for the import, if any
+	Line  int      `json:"line"`           // Line number where the import is declared
+	Range GFPRange `json:"range"`          // Source range of the import spec
 }
 
 // GFPConstant represents a constant declaration.
 type GFPConstant struct {
-	Name  string // Name of the constant
-	Type  string // Type of the constant (may be empty if inferred)
-	Value string // Value of the constant
-	Doc   string // Associated documentation comment
-	Line  int    // Line number where the constant is declared
+	Name       string         `json:"name"`                 // Name of the constant
+	Type       string         `json:"type,omitempty"`       // Type of the constant, repeated from the previous spec for implicit repetition (may be empty if inferred)
+	Value      string         `json:"value,omitempty"`      // Value of the constant
+	ExactValue string         `json:"exactValue,omitempty"` // Exact evaluated value (e.g. "3", "1/10" or "\"text\""), empty if it could not be evaluated
+	Kind       string         `json:"kind,omitempty"`       // Kind of the evaluated value: bool, string, int, float or complex
+	Doc        string         `json:"doc,omitempty"`        // Documentation comment of the spec, or of the declaration if it declares a single spec
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	GroupDoc   string         `json:"groupDoc,omitempty"`   // Documentation comment of the enclosing parenthesized declaration group
+	Comment    string         `json:"comment,omitempty"`    // Trailing line comment
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the constant is declared
+	Range      GFPRange       `json:"range"`                // Source range of the declaration
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
 }
 
 // GFPVariable represents a variable declaration.
 type GFPVariable struct {
-	Name  string // Name of the variable
-	Type  string // Type of the variable (may be empty if inferred)
-	Value string // Initial value of the variable (may be empty)
-	Doc   string // Associated documentation comment
-	Line  int    // Line number where the variable is declared
+	Name       string         `json:"name"`                 // Name of the variable
+	Type       string         `json:"type,omitempty"`       // Type of the variable (may be empty if inferred)
+	Value      string         `json:"value,omitempty"`      // Initial value of the variable (may be empty)
+	Doc        string         `json:"doc,omitempty"`        // Documentation comment of the spec, or of the declaration if it declares a single spec
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	GroupDoc   string         `json:"groupDoc,omitempty"`   // Documentation comment of the enclosing parenthesized declaration group
+	Comment    string         `json:"comment,omitempty"`    // Trailing line comment
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the variable is declared
+	Range      GFPRange       `json:"range"`                // Source range of the declaration
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
 }
 
 // GFPType represents a type definition.
 type GFPType struct {
-	Name string // Name of the type
-	Def  string // Definition of the type
-	Doc  string // Associated documentation comment
-	Line int    // Line number where the type is declared
+	Name       string         `json:"name"`                 // Name of the type
+	TypeParams []GFPTypeParam `json:"typeParams,omitempty"` // Type parameters of a generic type
+	Def        string         `json:"def"`                  // Definition of the type
+	Alias      bool           `json:"alias,omitempty"`      // Whether the declaration is an alias ("type X = Y")
+	Doc        string         `json:"doc,omitempty"`        // Documentation comment of the spec, or of the declaration if it declares a single spec
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	GroupDoc   string         `json:"groupDoc,omitempty"`   // Documentation comment of the enclosing parenthesized declaration group
+	Comment    string         `json:"comment,omitempty"`    // Trailing line comment
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the type is declared
+	Fields     []GFPField     `json:"fields,omitempty"`     // Fields of the type, if it is a struct
+	Methods    []GFPMethod    `json:"methods,omitempty"`    // Methods declared on the type (only populated in a GFPPackage)
+	Range      GFPRange       `json:"range"`                // Source range of the declaration
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
+}
+
+// GFPField represents a field of a struct type.
+type GFPField struct {
+	Name       string         `json:"name"`                 // Name of the field (the type name for embedded fields)
+	Type       string         `json:"type"`                 // Type of the field
+	Tag        string         `json:"tag,omitempty"`        // Raw struct tag without the surrounding quotes (e.g. `json:"name,omitempty"`)
+	Tags       []GFPTag       `json:"tags,omitempty"`       // Key/value pairs parsed from the struct tag, in declaration order
+	Embedded   bool           `json:"embedded,omitempty"`   // Whether the field is embedded
+	Exported   bool           `json:"exported,omitempty"`   // Whether the field is exported
+	Doc        string         `json:"doc,omitempty"`        // Associated documentation comment
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	Comment    string         `json:"comment,omitempty"`    // Trailing line comment
+	TypeInfo   *GFPTypeInfo   `json:"typeInfo,omitempty"`   // Resolved type information (only set in type-checked mode)
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the field is declared
+	Range      GFPRange       `json:"range"`                // Source range of the field
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
+}
+
+// GFPTag represents a single key/value pair of a struct tag.
+type GFPTag struct {
+	Key   string `json:"key"`             // Tag key (e.g. "json")
+	Value string `json:"value,omitempty"` // Unquoted tag value (e.g. "name,omitempty")
 }
 
 // GFPFunction represents a function declaration.
 type GFPFunction struct {
-	Name       string          // Name of the function
-	Parameters []GFPParameter // List of parameters
-	ReturnType string          // Return type(s)
-	Body       string          // Function body
-	Doc        string          // Associated documentation comment
-	Line       int             // Line number where the function is declared
+	Name       string         `json:"name"`                 // Name of the function
+	TypeParams []GFPTypeParam `json:"typeParams,omitempty"` // Type parameters of a generic function
+	Parameters []GFPParameter `json:"parameters,omitempty"` // List of parameters
+	Results    []GFPParameter `json:"results,omitempty"`    // List of results (names are empty for unnamed results)
+	Body       string         `json:"body,omitempty"`       // Function body
+	Calls      []GFPCall      `json:"calls,omitempty"`      // Call sites in the body, in source order
+	Doc        string         `json:"doc,omitempty"`        // Associated documentation comment
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the function is declared
+	Range      GFPRange       `json:"range"`                // Source range of the declaration, from the func keyword to the closing brace
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
+	BodyRange  GFPRange       `json:"bodyRange"`            // Source range of the body including its braces
 }
 
 // GFPMethod represents a method declaration.
 type GFPMethod struct {
-	Receiver   string          // Receiver type
-	Name       string          // Name of the method
-	Parameters []GFPParameter // List of parameters
-	ReturnType string          // Return type(s)
-	Body       string          // Method body
-	Doc        string          // Associated documentation comment
-	Line       int             // Line number where the method is declared
+	Receiver   string         `json:"receiver,omitempty"`   // Receiver type
+	Name       string         `json:"name"`                 // Name of the method
+	TypeParams []GFPTypeParam `json:"typeParams,omitempty"` // Type parameters of a generic receiver (constraints are only known in a GFPPackage)
+	Parameters []GFPParameter `json:"parameters,omitempty"` // List of parameters
+	Results    []GFPParameter `json:"results,omitempty"`    // List of results (names are empty for unnamed results)
+	Body       string         `json:"body,omitempty"`       // Method body
+	Calls      []GFPCall      `json:"calls,omitempty"`      // Call sites in the body, in source order
+	Doc        string         `json:"doc,omitempty"`        // Associated documentation comment
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the method is declared
+	Range      GFPRange       `json:"range"`                // Source range of the declaration, from the func keyword to the closing brace
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
+	BodyRange  GFPRange       `json:"bodyRange"`            // Source range of the body including its braces
 }
 
 // GFPInterface represents an interface declaration.
 type GFPInterface struct {
-	Name    string                // Name of the interface
-	Methods []GFPInterfaceMethod // List of methods in the interface
-	Doc     string                // Associated documentation comment
-	Line    int                   // Line number where the interface is declared
+	Name       string               `json:"name"`                 // Name of the interface
+	TypeParams []GFPTypeParam       `json:"typeParams,omitempty"` // Type parameters of a generic interface
+	Methods    []GFPInterfaceMethod `json:"methods,omitempty"`    // List of methods in the interface
+	Embeds     []string             `json:"embeds,omitempty"`     // Embedded interfaces (e.g. "io.Reader")
+	TypeSet    []GFPTypeSetEntry    `json:"typeSet,omitempty"`    // Type set elements such as "~int | ~string"
+	Doc        string               `json:"doc,omitempty"`        // Documentation comment of the spec, or of the declaration if it declares a single spec
+	DocComment *GFPDocComment       `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	GroupDoc   string               `json:"groupDoc,omitempty"`   // Documentation comment of the enclosing parenthesized declaration group
+	Comment    string               `json:"comment,omitempty"`    // Trailing line comment
+	Directives []GFPDirective       `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int                  `json:"line"`                 // Line number where the interface is declared
+	Range      GFPRange             `json:"range"`                // Source range of the declaration
+	DocRange   GFPRange             `json:"docRange"`             // Source range of the documentation comment
 }
 
 // GFPInterfaceMethod represents a method in an interface declaration.
 type GFPInterfaceMethod struct {
-	Name       string          // Name of the method
-	Parameters []GFPParameter // List of parameters
-	ReturnType string          // Return type(s)
-	Line       int             // Line number where the interface method is declared
+	Name       string         `json:"name"`                 // Name of the method
+	Parameters []GFPParameter `json:"parameters,omitempty"` // List of parameters
+	Results    []GFPParameter `json:"results,omitempty"`    // List of results (names are empty for unnamed results)
+	Doc        string         `json:"doc,omitempty"`        // Associated documentation comment
+	DocComment *GFPDocComment `json:"docComment,omitempty"` // Documentation comment parsed into blocks, with its synopsis and deprecation notice
+	Directives []GFPDirective `json:"directives,omitempty"` // Compiler directives and tool markers annotating the declaration
+	Line       int            `json:"line"`                 // Line number where the interface method is declared
+	Range      GFPRange       `json:"range"`                // Source range of the method element
+	DocRange   GFPRange       `json:"docRange"`             // Source range of the documentation comment
 }
 
-// GFPParameter represents a function or method parameter.
+// GFPParameter represents a function or method parameter or result.
 type GFPParameter struct {
-	Name string // Name of the parameter (may be empty for unnamed parameters)
-	Type string // Type of the parameter
+	Name     string       `json:"name,omitempty"`     // Name of the parameter (may be empty for unnamed parameters)
+	Type     string       `json:"type"`               // Type of the parameter (the element type for variadic parameters)
+	Variadic bool         `json:"variadic,omitempty"` // Whether the parameter is variadic ("...T")
+	TypeInfo *GFPTypeInfo `json:"typeInfo,omitempty"` // Resolved type information (only set in type-checked mode)
+}
+
+// GFPTypeInfo represents type information resolved with go/types.
+type GFPTypeInfo struct {
+	Type    string `json:"type"`              // Fully qualified type (e.g. "net/http.Handler" or "[]example.com/mod/pkg.Item")
+	Kind    string `json:"kind"`              // Kind of the underlying type: basic, pointer, slice, array, map, chan, func, struct, interface or typeparam
+	Package string `json:"package,omitempty"` // Import path of the package defining the named type, after dereferencing pointers (empty for unnamed and predeclared types)
+}
+
+// GFPTypeParam represents a type parameter of a generic declaration.
+type GFPTypeParam struct {
+	Name       string        `json:"name"`                 // Name of the type parameter
+	Constraint string        `json:"constraint,omitempty"` // Constraint of the type parameter (e.g. "any" or "~int | ~string")
+	Terms      []GFPTypeTerm `json:"terms,omitempty"`      // Terms of the constraint, if it is a union or approximation element
+}
+
+// GFPTypeSetEntry represents a type set element of an interface, such as "~int | ~string".
+// An interface's type set is the intersection of all its entries.
+type GFPTypeSetEntry struct {
+	Terms []GFPTypeTerm `json:"terms,omitempty"` // Terms of the union, in declaration order
+	Line  int           `json:"line"`            // Line number where the element is declared
+}
+
+// GFPTypeTerm represents a single term of a type union such as "~int | string".
+type GFPTypeTerm struct {
+	Type  string `json:"type"`            // Type of the term
+	Tilde bool   `json:"tilde,omitempty"` // Whether the term is an approximation ("~T")
+}
+
+// GFPDiagnostic represents a problem reported while parsing a file.
+type GFPDiagnostic struct {
+	File    string `json:"file"`    // Name of the file the problem was found in
+	Line    int    `json:"line"`    // Line number of the problem
+	Column  int    `json:"column"`  // Column number of the problem
+	Message string `json:"message"` // Description of the problem
+}
+
+// GFPCall represents a call site in a function or method body.
+type GFPCall struct {
+	Callee       string `json:"callee"`                 // Name of the called function or method ("func literal at <line>:<column>" for function literals, source text for other callable expressions)
+	Selector     string `json:"selector,omitempty"`     // Expression the callee is selected from (e.g. "fmt" or "s.store"), empty for plain calls
+	Package      string `json:"package,omitempty"`      // Import path of the package when Selector names an import
+	ReceiverType string `json:"receiverType,omitempty"` // Declared type of Selector when it is a parameter or the receiver, with import qualifiers replaced by paths
+	Line         int    `json:"line"`                   // Line number of the call
+	ViaInterface bool   `json:"viaInterface,omitempty"` // Whether the call is dispatched through an interface (only set in a GFPCallGraph)
+	ViaFuncValue bool   `json:"viaFuncValue,omitempty"` // Whether a function value (variable, parameter or field) is called
+}
+
+// GFPCallGraph represents the static call graph of a set of packages.
+// Functions are named "<import path>.<name>", methods "<import path>.<type>.<name>".
+type GFPCallGraph struct {
+	Nodes []string      `json:"nodes,omitempty"` // Qualified names of all functions and methods declared in the packages
+	Edges []GFPCallEdge `json:"edges,omitempty"` // All call sites, in declaration order
+
+	index   sync.Once        // Builds callers and callees from Edges on first use
+	callers map[string][]int // Edge indices by callee
+	callees map[string][]int // Edge indices by caller
+}
+
+// GFPCallEdge represents a single call from one function or method to another.
+type GFPCallEdge struct {
+	Caller string  `json:"caller"` // Qualified name of the calling function or method
+	Callee string  `json:"callee"` // Qualified name of the callee if it could be resolved, its source text otherwise
+	Call   GFPCall `json:"call"`   // The call site
+}
+
+// GFPRange represents a range of source code, from Start up to (but excluding) End.
+// A zero GFPRange means the element is not present in the source.
+type GFPRange struct {
+	Start GFPPosition `json:"start"` // Position of the first character
+	End   GFPPosition `json:"end"`   // Position immediately after the last character
+}
+
+// GFPPosition represents a position in a source file.
+type GFPPosition struct {
+	Line   int `json:"line"`   // Line number, starting at 1
+	Column int `json:"column"` // Column number in bytes, starting at 1
+	Offset int `json:"offset"` // Byte offset into the file content, starting at 0
 }
 
 // GFPComment represents a comment in the Go file.
 type GFPComment struct {
-	Text string // Text of the comment
-	Line int    // Line number where the comment appears
+	Text string `json:"text"`           // Text of the comment
+	Line int    `json:"line"`           // Line number where the comment appears
+	Kind string `json:"kind"`           // Kind of the comment: free (free-floating), doc, trailing or body (inside a declaration)
+	Decl string `json:"decl,omitempty"` // Declaration a doc, trailing or body comment belongs to ("Type.Method" for methods), empty for the file and import docs and group docs of several specs
 }
@@ -6,6 +6,7 @@ import (
 	"go/format"
 	"go/token"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -65,5 +66,265 @@ func blockStmtToString(block *ast.BlockStmt) string {
 // This function checks if a file name ends with "_test.go", which is the
 // convention for Go test files.
 func isTestFile(filePath string) bool {
-	return filepath.Ext(filePath) == ".go" && filepath.Base(filePath)[len(filepath.Base(filePath))-8:] == "_test.go"
+	return strings.HasSuffix(filepath.Base(filePath), "_test.go")
+}
+
+// unquote removes the quotes from a Go string literal.
+//
+// Parameters:
+//   - lit: string - The string literal, including its quotes or backquotes.
+//
+// Returns:
+//   - string: The unquoted value.
+//
+// If the literal cannot be unquoted, it is returned unchanged.
+func unquote(lit string) string {
+	if s, err := strconv.Unquote(lit); err == nil {
+		return s
+	}
+	return lit
+}
+
+// parseStructTag splits a struct tag into its key/value pairs.
+//
+// Parameters:
+//   - tag: string - The unquoted struct tag (e.g. `json:"name,omitempty" db:"name"`).
+//
+// Returns:
+//   - []GFPTag: The key/value pairs in the order they appear.
+//
+// This function follows the conventional format understood by reflect.StructTag.
+// Parsing stops at the first malformed pair, keeping the pairs found before it.
+func parseStructTag(tag string) []GFPTag {
+	var tags []GFPTag
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			break
+		}
+		tag = tag[i+1:]
+
+		tags = append(tags, GFPTag{Key: key, Value: value})
+	}
+	return tags
+}
+
+// embeddedFieldName returns the field name of an embedded field type.
+//
+// Parameters:
+//   - expr: ast.Expr - The type expression of the embedded field.
+//
+// Returns:
+//   - string: The unqualified type name (e.g. "Mutex" for *sync.Mutex).
+//
+// Pointers, package qualifiers and type arguments are stripped, matching the name
+// the Go specification gives to embedded fields.
+func embeddedFieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return embeddedFieldName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedFieldName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(e.X)
+	case *ast.ParenExpr:
+		return embeddedFieldName(e.X)
+	}
+	return exprToString(expr)
+}
+
+// predeclaredTypes holds the predeclared types that are not interfaces.
+var predeclaredTypes = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true,
+	"float32": true, "float64": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true,
+	"uint32": true, "uint64": true, "uintptr": true,
+}
+
+// isEmbeddedInterface reports whether an interface element without a name embeds another interface.
+//
+// Parameters:
+//   - expr: ast.Expr - The type expression of the interface element.
+//
+// Returns:
+//   - bool: true if the element names a (possibly qualified or instantiated) type, false otherwise.
+//
+// Without type information a named type cannot be told apart from an interface, so every
+// named type except the predeclared non-interface types counts as an embedded interface.
+// Unions, approximations ("~T") and type literals are type set elements instead.
+func isEmbeddedInterface(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return !predeclaredTypes[e.Name]
+	case *ast.SelectorExpr:
+		return true
+	case *ast.IndexExpr:
+		return isEmbeddedInterface(e.X)
+	case *ast.IndexListExpr:
+		return isEmbeddedInterface(e.X)
+	case *ast.ParenExpr:
+		return isEmbeddedInterface(e.X)
+	}
+	return false
+}
+
+// nodeRange returns the source range covered by an AST node.
+//
+// Parameters:
+//   - fset: *token.FileSet - The file set the node was parsed with.
+//   - node: ast.Node - The node whose range is wanted.
+//
+// Returns:
+//   - GFPRange: The range from the start of the node to just after its end.
+func nodeRange(fset *token.FileSet, node ast.Node) GFPRange {
+	return GFPRange{
+		Start: newPosition(fset.Position(node.Pos())),
+		End:   newPosition(fset.Position(node.End())),
+	}
+}
+
+// specRange returns the source range of a spec within a GenDecl.
+//
+// Parameters:
+//   - fset: *token.FileSet - The file set the declaration was parsed with.
+//   - decl: *ast.GenDecl - The declaration containing the spec.
+//   - spec: ast.Spec - The spec whose range is wanted.
+//
+// Returns:
+//   - GFPRange: The range of the spec.
+//
+// For declarations without parentheses (e.g. "const X = 1") the range covers the whole
+// declaration including its keyword; inside a group it only covers the spec.
+func specRange(fset *token.FileSet, decl *ast.GenDecl, spec ast.Spec) GFPRange {
+	if !decl.Lparen.IsValid() {
+		return nodeRange(fset, decl)
+	}
+	return nodeRange(fset, spec)
+}
+
+// commentRange returns the source range of a comment group.
+//
+// Parameters:
+//   - fset: *token.FileSet - The file set the comment was parsed with.
+//   - group: *ast.CommentGroup - The comment group, which may be nil.
+//
+// Returns:
+//   - GFPRange: The range of the comment group, or the zero range if group is nil.
+func commentRange(fset *token.FileSet, group *ast.CommentGroup) GFPRange {
+	if group == nil {
+		return GFPRange{}
+	}
+	return nodeRange(fset, group)
+}
+
+// bodyRange returns the source range of a function body including its braces.
+//
+// Parameters:
+//   - fset: *token.FileSet - The file set the body was parsed with.
+//   - body: *ast.BlockStmt - The body, which is nil for functions declared without one.
+//
+// Returns:
+//   - GFPRange: The range of the body, or the zero range if body is nil.
+func bodyRange(fset *token.FileSet, body *ast.BlockStmt) GFPRange {
+	if body == nil {
+		return GFPRange{}
+	}
+	return nodeRange(fset, body)
+}
+
+// newPosition converts a token.Position into a GFPPosition.
+func newPosition(pos token.Position) GFPPosition {
+	return GFPPosition{
+		Line:   pos.Line,
+		Column: pos.Column,
+		Offset: pos.Offset,
+	}
+}
+
+// isStandardImportPath reports whether an import path belongs to the standard library.
+//
+// Parameters:
+//   - importPath: string - The import path to check.
+//
+// Returns:
+//   - bool: true if the first path element contains no dot, false otherwise.
+//
+// This is the same heuristic the go tool uses: only standard library paths may omit
+// a domain name in their first element.
+func isStandardImportPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return first != "" && !strings.Contains(first, ".")
+}
+
+// signatureString renders the parameter and result types of a signature.
+//
+// Parameters:
+//   - params: []GFPParameter - The parameters of the signature.
+//   - results: []GFPParameter - The results of the signature.
+//
+// Returns:
+//   - string: The signature without names, e.g. "(string, ...int) (int, error)".
+func signatureString(params, results []GFPParameter) string {
+	s := "(" + strings.Join(parameterTypes(params), ", ") + ")"
+	switch len(results) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + parameterTypes(results)[0]
+	}
+	return s + " (" + strings.Join(parameterTypes(results), ", ") + ")"
+}
+
+// parameterTypes returns the types of params as written in a signature, with a
+// "..." prefix for variadic parameters.
+func parameterTypes(params []GFPParameter) []string {
+	types := make([]string, len(params))
+	for i, param := range params {
+		types[i] = param.Type
+		if param.Variadic {
+			types[i] = "..." + param.Type
+		}
+	}
+	return types
+}
+
+// unparen removes any enclosing parentheses from an expression.
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
 }
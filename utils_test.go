@@ -3,6 +3,7 @@ package gofileparser
 import (
 	"go/ast"
 	"go/token"
+	"reflect"
 	"testing"
 )
 
@@ -119,6 +120,11 @@ func TestIsTestFile(t *testing.T) {
 			filePath: "example.txt",
 			expected: false,
 		},
+		{
+			name:     "Short file name",
+			filePath: "dir/a.go",
+			expected: false,
+		},
 		{
 			name:     "File with _test in the middle",
 			filePath: "example_test_file.go",
@@ -135,3 +141,177 @@ func TestIsTestFile(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStructTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		expected []GFPTag
+	}{
+		{
+			name:     "Empty tag",
+			tag:      "",
+			expected: nil,
+		},
+		{
+			name:     "Single key",
+			tag:      `json:"name,omitempty"`,
+			expected: []GFPTag{{Key: "json", Value: "name,omitempty"}},
+		},
+		{
+			name:     "Multiple keys with escapes",
+			tag:      `json:"id" db:"user_id" regex:"a\"b"`,
+			expected: []GFPTag{{Key: "json", Value: "id"}, {Key: "db", Value: "user_id"}, {Key: "regex", Value: `a"b`}},
+		},
+		{
+			name:     "Malformed rest",
+			tag:      `json:"id" broken`,
+			expected: []GFPTag{{Key: "json", Value: "id"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseStructTag(tt.tag)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseStructTag() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmbeddedFieldName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     ast.Expr
+		expected string
+	}{
+		{
+			name:     "Identifier",
+			expr:     ast.NewIdent("Base"),
+			expected: "Base",
+		},
+		{
+			name:     "Qualified pointer",
+			expr:     &ast.StarExpr{X: &ast.SelectorExpr{X: ast.NewIdent("sync"), Sel: ast.NewIdent("Mutex")}},
+			expected: "Mutex",
+		},
+		{
+			name:     "Generic type",
+			expr:     &ast.IndexExpr{X: ast.NewIdent("List"), Index: ast.NewIdent("int")},
+			expected: "List",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := embeddedFieldName(tt.expr)
+			if result != tt.expected {
+				t.Errorf("embeddedFieldName() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsEmbeddedInterface(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     ast.Expr
+		expected bool
+	}{
+		{
+			name:     "Local interface",
+			expr:     ast.NewIdent("Closer"),
+			expected: true,
+		},
+		{
+			name:     "Qualified interface",
+			expr:     &ast.SelectorExpr{X: ast.NewIdent("io"), Sel: ast.NewIdent("Reader")},
+			expected: true,
+		},
+		{
+			name:     "Predeclared interface",
+			expr:     ast.NewIdent("error"),
+			expected: true,
+		},
+		{
+			name:     "Predeclared basic type",
+			expr:     ast.NewIdent("int"),
+			expected: false,
+		},
+		{
+			name:     "Approximation",
+			expr:     &ast.UnaryExpr{Op: token.TILDE, X: ast.NewIdent("string")},
+			expected: false,
+		},
+		{
+			name:     "Slice literal",
+			expr:     &ast.ArrayType{Elt: ast.NewIdent("byte")},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isEmbeddedInterface(tt.expr)
+			if result != tt.expected {
+				t.Errorf("isEmbeddedInterface() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsStandardImportPath(t *testing.T) {
+	tests := []struct {
+		importPath string
+		expected   bool
+	}{
+		{"fmt", true},
+		{"net/http", true},
+		{"github.com/tealwp/gofileparser", false},
+		{"example.com", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			if result := isStandardImportPath(tt.importPath); result != tt.expected {
+				t.Errorf("isStandardImportPath() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignatureString(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   []GFPParameter
+		results  []GFPParameter
+		expected string
+	}{
+		{
+			name:     "No parameters or results",
+			expected: "()",
+		},
+		{
+			name:     "Variadic with single result",
+			params:   []GFPParameter{{Name: "format", Type: "string"}, {Name: "args", Type: "any", Variadic: true}},
+			results:  []GFPParameter{{Type: "error"}},
+			expected: "(string, ...any) error",
+		},
+		{
+			name:     "Named results",
+			results:  []GFPParameter{{Name: "n", Type: "int"}, {Name: "err", Type: "error"}},
+			expected: "() (int, error)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := signatureString(tt.params, tt.results)
+			if result != tt.expected {
+				t.Errorf("signatureString() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}